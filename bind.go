@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"reflect"
@@ -14,7 +15,18 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-type CustomBinder struct{}
+const (
+	// defaultMemory limits the total size of form values read from a streamed multipart body.
+	defaultMemory = 32 << 20
+	// maxFormParts limits the number of form values read from a streamed multipart body.
+	maxFormParts = 1000
+)
+
+type CustomBinder struct {
+	// Principal extracts authenticated principal for `auth` tagged fields,
+	// value stored in echo context under "user" key is used by default.
+	Principal func(c echo.Context) (any, error)
+}
 
 // Bind implements the `Binder#Bind` function.
 // Binding is done in following order: 1) path params; 2) query params; 3) request body. Each step COULD override previous
@@ -32,7 +44,115 @@ func (b *CustomBinder) Bind(i interface{}, c echo.Context) (err error) {
 	if err = b.BindQueryParams(c, i); err != nil {
 		return err
 	}
-	return b.BindBody(c, i)
+	if err = b.BindBody(c, i); err != nil {
+		return err
+	}
+	return b.BindContext(c, i)
+}
+
+// BindRequest binds path params, headers, cookies, query params and body of r to i outside of echo routing,
+// e.g. in net/http handlers. Path params are taken from pathParams, e.g. values of r.PathValue.
+func (b *CustomBinder) BindRequest(r *http.Request, pathParams map[string]string, i interface{}) error {
+	params := map[string][]string{}
+	for name, value := range pathParams {
+		params[name] = []string{value}
+	}
+	if err := b.bindData(i, params, "path"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	c := requestEcho.NewContext(r, nil)
+	if err := b.BindHeaders(c, i); err != nil {
+		return err
+	}
+	if err := b.BindCookies(c, i); err != nil {
+		return err
+	}
+	if err := b.BindQueryParams(c, i); err != nil {
+		return err
+	}
+	if err := b.BindBody(c, i); err != nil {
+		return err
+	}
+	return b.BindContext(c, i)
+}
+
+// requestEcho provides JSON serializer to contexts of BindRequest.
+var requestEcho = echo.New()
+
+// BindContext binds values set in echo context by middleware to `ctx` tagged fields, e.g. `ctx:"user"`,
+// and authenticated principal to `auth` tagged fields. Missing principal fails with 401 Unauthorized
+// unless the field is tagged with `auth:"optional"`.
+func (b *CustomBinder) BindContext(c echo.Context, i interface{}) error {
+	val := reflect.ValueOf(i)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return b.bindContext(c, val.Elem())
+}
+
+func (b *CustomBinder) bindContext(c echo.Context, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		structField := val.Field(i)
+		if !structField.CanSet() {
+			continue
+		}
+		if typeField.Anonymous && structField.Kind() == reflect.Struct {
+			if err := b.bindContext(c, structField); err != nil {
+				return err
+			}
+			continue
+		}
+		if key, ok := typeField.Tag.Lookup("ctx"); ok {
+			if err := setContextValue(structField, c.Get(key)); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("ctx %q: %s", key, err)).SetInternal(err)
+			}
+		}
+		if auth, ok := typeField.Tag.Lookup("auth"); ok {
+			principal, err := b.principal(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error()).SetInternal(err)
+			}
+			if principal == nil && auth != "optional" {
+				return echo.ErrUnauthorized
+			}
+			if err := setContextValue(structField, principal); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("auth: %s", err)).SetInternal(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *CustomBinder) principal(c echo.Context) (any, error) {
+	if b.Principal != nil {
+		return b.Principal(c)
+	}
+	return c.Get("user"), nil
+}
+
+// setContextValue assigns value to field, pointers are taken or dereferenced when needed,
+// nil value resets the field.
+func setContextValue(field reflect.Value, value any) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case field.Kind() == reflect.Ptr && v.Type().AssignableTo(field.Type().Elem()):
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().Set(v)
+		field.Set(ptr)
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Type().AssignableTo(field.Type()):
+		field.Set(v.Elem())
+	default:
+		return fmt.Errorf("value of type %T is not assignable to %s", value, field.Type())
+	}
+	return nil
 }
 
 // BindHeaders binds HTTP headers to a bindable object
@@ -63,6 +183,9 @@ func (b *CustomBinder) BindBody(c echo.Context, i interface{}) (err error) {
 	}
 
 	ctype := req.Header.Get(echo.HeaderContentType)
+	if p, ok := patchField(reflect.ValueOf(i).Elem()); ok {
+		return b.bindPatch(req, p, ctype)
+	}
 	switch {
 	case strings.HasPrefix(ctype, echo.MIMEApplicationJSON):
 		if err = c.Echo().JSONSerializer.Deserialize(c, i); err != nil {
@@ -91,6 +214,9 @@ func (b *CustomBinder) BindBody(c echo.Context, i interface{}) (err error) {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
 		}
 	case strings.HasPrefix(ctype, echo.MIMEMultipartForm):
+		if _, ok := filePartField(reflect.ValueOf(i).Elem(), ""); ok {
+			return b.bindMultipartStream(req, i)
+		}
 		form, err := c.MultipartForm()
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
@@ -107,6 +233,81 @@ func (b *CustomBinder) BindBody(c echo.Context, i interface{}) (err error) {
 	return nil
 }
 
+// bindPatch binds whole request body to the Patch field.
+func (b *CustomBinder) bindPatch(req *http.Request, p patcher, ctype string) error {
+	mediaType := MIMEApplicationMergePatchJSON
+	switch {
+	case strings.HasPrefix(ctype, MIMEApplicationJSONPatchJSON):
+		mediaType = MIMEApplicationJSONPatchJSON
+	case strings.HasPrefix(ctype, MIMEApplicationMergePatchJSON), strings.HasPrefix(ctype, echo.MIMEApplicationJSON):
+	default:
+		return echo.ErrUnsupportedMediaType
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	if err := p.setPatch(mediaType, body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+// bindMultipartStream binds form fields preceding the first file part and hands the rest of
+// the upload to the FilePart field without buffering it.
+func (b *CustomBinder) bindMultipartStream(req *http.Request, i interface{}) error {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	values := map[string][]string{}
+	remaining, count := int64(defaultMemory), 0
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+		if part.FileName() == "" {
+			if _, ok := filePartField(reflect.ValueOf(i).Elem(), part.FormName()); ok {
+				err := fmt.Errorf("part %q must be a file", part.FormName())
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+			if count++; count > maxFormParts {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("more than %d form values", maxFormParts))
+			}
+			value, err := io.ReadAll(io.LimitReader(part, remaining+1))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+			if remaining -= int64(len(value)); remaining < 0 {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("form values exceed %d bytes", defaultMemory))
+			}
+			values[part.FormName()] = append(values[part.FormName()], string(value))
+			continue
+		}
+		field, ok := filePartField(reflect.ValueOf(i).Elem(), part.FormName())
+		if !ok {
+			err := fmt.Errorf("unexpected file part %q", part.FormName())
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+		field.Set(reflect.ValueOf(FilePart{Part: part, reader: reader}))
+		break
+	}
+	if name, ok := requiredFilePart(reflect.TypeOf(i).Elem()); ok {
+		if field, _ := filePartField(reflect.ValueOf(i).Elem(), name); field.Interface().(FilePart).Part == nil {
+			err := fmt.Errorf("file part %q is required", name)
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	}
+	if err := b.bindData(i, values, "formData"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
 func (b *CustomBinder) bindFile(destination interface{}, data map[string][]*multipart.FileHeader, tag string) error {
 	if destination == nil || len(data) == 0 {
 		return nil
@@ -293,6 +494,13 @@ func (b *CustomBinder) bindData(destination interface{}, data map[string][]strin
 			continue
 		}
 
+		if unmarshaler, ok := structField.Addr().Interface().(paramsUnmarshaler); ok {
+			if err := unmarshaler.UnmarshalParams(inputValue); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Call this first, in case we're dealing with an alias to an array type
 		if ok, err := unmarshalField(typeField.Type.Kind(), inputValue[0], structField); ok {
 			if err != nil {
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBindRequest(t *testing.T) {
+	type input struct {
+		ID      int64  `path:"id"`
+		Limit   int    `query:"limit"`
+		Tenant  string `header:"X-Tenant"`
+		Session string `cookie:"session"`
+		Name    string `json:"name"`
+		User    string `auth:"user"`
+	}
+	b := &CustomBinder{Principal: func(c echo.Context) (any, error) {
+		return c.Request().Header.Get(echo.HeaderAuthorization), nil
+	}}
+
+	req := httptest.NewRequest(http.MethodPost, "/users/7?limit=3", strings.NewReader(`{"name":"n"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Tenant", "t")
+	req.Header.Set(echo.HeaderAuthorization, "u")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+	var in input
+	if err := b.BindRequest(req, map[string]string{"id": "7"}, &in); err != nil {
+		t.Fatal(err)
+	}
+	want := input{ID: 7, Limit: 3, Tenant: "t", Session: "s", Name: "n", User: "u"}
+	if in != want {
+		t.Errorf("got %+v, want %+v", in, want)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/x", nil)
+	err := b.BindRequest(req, map[string]string{"id": "x"}, &input{})
+	var he *echo.HTTPError
+	if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("got error %v, want 400", err)
+	}
+}
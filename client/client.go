@@ -0,0 +1,65 @@
+// Package client is the runtime of generated Go clients. It encodes input structs tagged the
+// same way as Interactor inputs into requests and decodes responses into outputs.
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Client calls operations of a service at BaseURL.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New creates Client of service at baseURL, e.g. `http://localhost:1323/api`.
+func New(baseURL string, httpClient ...*http.Client) *Client {
+	c := &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient}
+	if len(httpClient) > 0 {
+		c.HTTPClient = httpClient[0]
+	}
+	return c
+}
+
+// Do calls operation at path pattern, e.g. `/users/{id}`, with input in and decodes response into out.
+func (c *Client) Do(ctx context.Context, method, pattern string, in, out any) error {
+	req, err := NewRequest(ctx, method, c.BaseURL+pattern, in)
+	if err != nil {
+		return err
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return DecodeResponse(res, out)
+}
+
+// Error is error response of service, see rest.ErrResponse.
+type Error struct {
+	StatusCode int            `json:"-"`
+	Status     string         `json:"status,omitempty"`
+	Code       int            `json:"code,omitempty"`
+	Message    string         `json:"error,omitempty"`
+	Context    map[string]any `json:"context,omitempty"`
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.Status
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, message)
+}
+
+// HTTPStatus returns HTTP status code of response.
+func (e *Error) HTTPStatus() int {
+	return e.StatusCode
+}
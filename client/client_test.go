@@ -0,0 +1,55 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fourcels/rest"
+	"github.com/fourcels/rest/client"
+	"github.com/labstack/echo/v4"
+)
+
+type numbers struct {
+	ID     rest.Opt[int64]     `path:"id"`
+	N      rest.Opt[int64]     `query:"n"`
+	Max    rest.Opt[uint64]    `query:"max"`
+	List   rest.Opt[[]int64]   `query:"list"`
+	Header rest.Opt[int64]     `header:"X-N"`
+	At     rest.Opt[time.Time] `query:"at"`
+}
+
+func TestDoOpt(t *testing.T) {
+	s := rest.New(rest.WithBaseURL("/api"), rest.WithMiddleware())
+	s.GET("/numbers/{id}", rest.NewHandler(func(c echo.Context, in numbers, out *string) error {
+		id, _ := in.ID.Get()
+		n, _ := in.N.Get()
+		max, _ := in.Max.Get()
+		list, _ := in.List.Get()
+		header, _ := in.Header.Get()
+		at, _ := in.At.Get()
+		*out = fmt.Sprintf("%d %d %d %v %d %s", id, n, max, list, header, at.UTC().Format(time.RFC3339))
+		return nil
+	}))
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	in := numbers{
+		ID:     rest.NewOpt[int64](9007199254740993),
+		N:      rest.NewOpt[int64](123456789),
+		Max:    rest.NewOpt[uint64](18446744073709551615),
+		List:   rest.NewOpt([]int64{1, 123456789012}),
+		Header: rest.NewOpt[int64](-987654321098),
+		At:     rest.NewOpt(at),
+	}
+	var out string
+	if err := client.New(srv.URL+"/api").Do(context.Background(), "GET", "/numbers/{id}", in, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "9007199254740993 123456789 18446744073709551615 [1 123456789012] -987654321098 2024-05-06T07:08:09Z"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const (
+	mimeApplicationJSON           = "application/json"
+	mimeApplicationForm           = "application/x-www-form-urlencoded"
+	mimeApplicationMergePatchJSON = "application/merge-patch+json"
+)
+
+// File is uploaded in `formData` field of input.
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// optional is implemented by rest.Opt, its value is returned by method Get.
+type optional interface {
+	IsPresent() bool
+	IsNull() bool
+}
+
+// request collects parts of request from input fields.
+type request struct {
+	path     map[string]string
+	query    url.Values
+	header   http.Header
+	cookies  []*http.Cookie
+	json     map[string]any
+	form     url.Values
+	formData url.Values
+	files    map[string]*File
+	patch    []byte
+}
+
+// NewRequest creates request of input in to rawURL with `{param}` path pattern. Fields are
+// encoded by `path`, `query`, `header`, `cookie`, `json`, `form` and `formData` tags. Unset Opt
+// and nil pointers are omitted, zero values only if tagged `omitempty`, null Opt is sent as empty
+// parameter or JSON null. A json.RawMessage field tagged `patch` is sent as JSON Merge Patch.
+func NewRequest(ctx context.Context, method, rawURL string, in any) (*http.Request, error) {
+	r := &request{
+		path:     map[string]string{},
+		query:    url.Values{},
+		header:   http.Header{},
+		json:     map[string]any{},
+		form:     url.Values{},
+		formData: url.Values{},
+		files:    map[string]*File{},
+	}
+	if in != nil {
+		val := reflect.Indirect(reflect.ValueOf(in))
+		if val.Kind() == reflect.Struct {
+			if err := r.collect(val); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for name, value := range r.path {
+		rawURL = strings.ReplaceAll(rawURL, "{"+name+"}", url.PathEscape(value))
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	for name, values := range r.query {
+		query[name] = append(query[name], values...)
+	}
+	u.RawQuery = query.Encode()
+
+	body, contentType, err := r.body()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	return req, nil
+}
+
+func (r *request) collect(val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		value := val.Field(i)
+		if field.Anonymous && reflect.Indirect(value).Kind() == reflect.Struct {
+			if value.Kind() == reflect.Pointer && value.IsNil() {
+				continue
+			}
+			if err := r.collect(reflect.Indirect(value)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("patch"); ok {
+			if raw, ok := value.Interface().(json.RawMessage); ok && raw != nil {
+				r.patch = raw
+			}
+			continue
+		}
+		if name := tagName(field, "path"); name != "" {
+			values, err := format(value, false)
+			if err != nil {
+				return err
+			}
+			if len(values) > 0 {
+				r.path[name] = values[0]
+			}
+			continue
+		}
+		if name := tagName(field, "json"); name != "" {
+			if omitEmpty(field, "json") && value.IsZero() {
+				continue
+			}
+			if opt, ok := value.Interface().(optional); ok && !opt.IsPresent() {
+				continue
+			}
+			r.json[name] = value.Interface()
+			continue
+		}
+		if name := tagName(field, "formData"); name != "" {
+			if file, ok := fileValue(value); ok {
+				r.files[name] = file
+				continue
+			}
+		}
+		for tag, dest := range map[string]url.Values{"query": r.query, "form": r.form, "formData": r.formData} {
+			if name := tagName(field, tag); name != "" {
+				values, err := format(value, omitEmpty(field, tag))
+				if err != nil {
+					return err
+				}
+				for _, v := range values {
+					dest.Add(name, v)
+				}
+			}
+		}
+		if name := tagName(field, "header"); name != "" {
+			values, err := format(value, omitEmpty(field, "header"))
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				r.header.Add(name, v)
+			}
+		}
+		if name := tagName(field, "cookie"); name != "" {
+			values, err := format(value, omitEmpty(field, "cookie"))
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: v})
+			}
+		}
+	}
+	return nil
+}
+
+// body encodes body by the fields present: patch, files or formData, form, or JSON.
+func (r *request) body() (io.Reader, string, error) {
+	switch {
+	case r.patch != nil:
+		return bytes.NewReader(r.patch), mimeApplicationMergePatchJSON, nil
+	case len(r.files) > 0 || len(r.formData) > 0:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for name, values := range r.formData {
+			for _, v := range values {
+				if err := w.WriteField(name, v); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		for name, file := range r.files {
+			part, err := w.CreateFormFile(name, file.Name)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := io.Copy(part, file.Content); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, w.FormDataContentType(), nil
+	case len(r.form) > 0:
+		return strings.NewReader(r.form.Encode()), mimeApplicationForm, nil
+	case len(r.json) > 0:
+		body, err := json.Marshal(r.json)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(body), mimeApplicationJSON, nil
+	}
+	return nil, "", nil
+}
+
+// tagName returns name of field in tag, empty if field is not tagged.
+func tagName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	name = strings.TrimSpace(name)
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// omitEmpty reports whether field has `omitempty` option in tag.
+func omitEmpty(field reflect.StructField, tag string) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get(tag), ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+func fileValue(value reflect.Value) (*File, bool) {
+	switch file := value.Interface().(type) {
+	case *File:
+		return file, file != nil
+	case File:
+		return &file, true
+	}
+	return nil, false
+}
+
+// format returns parameter values of value, unset Opt, nil pointers and values marshaled to JSON
+// null are omitted, zero values only with omitEmpty. Null Opt is empty string.
+func format(value reflect.Value, omitEmpty bool) ([]string, error) {
+	if opt, ok := value.Interface().(optional); ok {
+		if !opt.IsPresent() {
+			return nil, nil
+		}
+		if opt.IsNull() {
+			return []string{""}, nil
+		}
+		// Value is formatted by its type, Get of Opt returns it with presence flag.
+		return format(value.MethodByName("Get").Call(nil)[0], false)
+	}
+	if ((value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && value.IsNil()) || (omitEmpty && value.IsZero()) {
+		return nil, nil
+	}
+	if m, ok := value.Interface().(json.Marshaler); ok {
+		// Values absent on server side, e.g. rest.FilePart, are encoded as null.
+		if raw, err := m.MarshalJSON(); err == nil && bytes.Equal(raw, []byte("null")) {
+			return nil, nil
+		}
+	}
+	if m, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return []string{string(text)}, err
+	}
+	switch value.Kind() {
+	case reflect.Pointer:
+		return format(value.Elem(), false)
+	case reflect.Slice, reflect.Array:
+		var res []string
+		for i := 0; i < value.Len(); i++ {
+			item, err := format(value.Index(i), false)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, item...)
+		}
+		return res, nil
+	}
+	return []string{fmt.Sprint(value.Interface())}, nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// DecodeResponse decodes response into out, fields tagged `header` and `cookie` are set from
+// response headers and cookies. Error status codes are returned as *Error.
+func DecodeResponse(res *http.Response, out any) error {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		e := &Error{StatusCode: res.StatusCode}
+		_ = json.Unmarshal(body, e)
+		return e
+	}
+	if out == nil {
+		return nil
+	}
+	if len(body) > 0 && res.StatusCode != http.StatusNoContent {
+		if err := json.Unmarshal(body, out); err != nil {
+			return err
+		}
+	}
+	val := reflect.Indirect(reflect.ValueOf(out))
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	return decodeHeaders(res, val)
+}
+
+func decodeHeaders(res *http.Response, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if name := tagName(field, "header"); name != "" {
+			if value := res.Header.Get(name); value != "" {
+				if err := setString(val.Field(i), value); err != nil {
+					return fmt.Errorf("header %s: %w", name, err)
+				}
+			}
+		}
+		if name := tagName(field, "cookie"); name != "" {
+			for _, cookie := range res.Cookies() {
+				if cookie.Name == name {
+					if err := setString(val.Field(i), cookie.Value); err != nil {
+						return fmt.Errorf("cookie %s: %w", name, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// setString sets value of field from parameter string.
+func setString(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	default:
+		return json.Unmarshal([]byte(value), field.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+// Package clientgen generates typed Go clients of operations registered on rest.Service.
+//
+// Operations have concrete input and output types, generated client has a method per operation
+// with copies of these types, requests are encoded and responses decoded by package client.
+package clientgen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"mime/multipart"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fourcels/rest"
+)
+
+const clientPkg = "github.com/fourcels/rest/client"
+
+var (
+	restPkg           = reflect.TypeOf(rest.Operation{}).PkgPath()
+	noContentType     = reflect.TypeOf(rest.NoContent{})
+	filePartType      = reflect.TypeOf(rest.FilePart{})
+	fileHeaderType    = reflect.TypeOf(multipart.FileHeader{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Config of generated client.
+type Config struct {
+	// Package is name of generated package, `client` by default.
+	Package string
+}
+
+// WriteFile generates client of ops into filename, see Generate.
+func WriteFile(filename string, ops []rest.Operation, cfg Config) error {
+	src, err := Generate(ops, cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, 0o644)
+}
+
+// Generate returns source of client with a method per operation, e.g. of Service.Operations.
+// Methods are named after operationId, or method and path if it is not set.
+func Generate(ops []rest.Operation, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		cfg.Package = "client"
+	}
+	g := &generator{
+		imports: map[string]string{},
+		aliases: map[string]bool{},
+		names:   map[reflect.Type]string{},
+		used:    map[string]bool{"Client": true, "New": true},
+	}
+	g.imp("context")
+	g.imp("net/http")
+	g.imp(clientPkg)
+
+	var methods bytes.Buffer
+	for _, op := range ops {
+		g.method(&methods, op)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by clientgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", cfg.Package)
+	// Standard library imports are grouped first like goimports does.
+	var std, other []string
+	for p := range g.imports {
+		if first, _, _ := strings.Cut(p, "/"); strings.Contains(first, ".") {
+			other = append(other, p)
+		} else {
+			std = append(std, p)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 && len(other) > 0 {
+			src.WriteString("\n")
+		}
+		for _, p := range group {
+			if alias := g.imports[p]; alias != path.Base(p) {
+				fmt.Fprintf(&src, "%s %q\n", alias, p)
+			} else {
+				fmt.Fprintf(&src, "%q\n", p)
+			}
+		}
+	}
+	fmt.Fprintf(&src, `)
+
+// Client calls operations of service.
+type Client struct {
+	*%[1]s.Client
+}
+
+// New creates Client of service at baseURL.
+func New(baseURL string, httpClient ...*http.Client) *Client {
+	return &Client{%[1]s.New(baseURL, httpClient...)}
+}
+`, g.imports[clientPkg])
+	src.Write(methods.Bytes())
+	src.Write(g.defs.Bytes())
+
+	res, err := format.Source(src.Bytes())
+	if err != nil {
+		return src.Bytes(), err
+	}
+	return res, nil
+}
+
+type generator struct {
+	// imports maps package path to its name in generated source.
+	imports map[string]string
+	aliases map[string]bool
+	// names of defined types.
+	names map[reflect.Type]string
+	used  map[string]bool
+	defs  bytes.Buffer
+}
+
+// imp imports package p and returns its name.
+func (g *generator) imp(p string) string {
+	if alias, ok := g.imports[p]; ok {
+		return alias
+	}
+	base := goIdent(path.Base(p))
+	alias := base
+	for i := 2; g.aliases[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	g.imports[p] = alias
+	g.aliases[alias] = true
+	return alias
+}
+
+func (g *generator) method(w *bytes.Buffer, op rest.Operation) {
+	base := op.ID
+	if base == "" {
+		base = strings.ToLower(op.Method) + " " + op.Path
+	}
+	name := g.unique(exportName(base))
+
+	inType := reflect.TypeOf(op.Input).Elem()
+	outType := reflect.TypeOf(op.Output).Elem()
+
+	params, in := "ctx context.Context", "nil"
+	if hasFields(inType) {
+		params += ", in " + g.top(inType, name+"Input")
+		in = "&in"
+	}
+	fmt.Fprintf(w, "\n// %s calls %s %s.", name, op.Method, op.Path)
+	if op.Summary != "" {
+		fmt.Fprintf(w, "\n// %s", op.Summary)
+	}
+	if outType == noContentType {
+		fmt.Fprintf(w, `
+func (c *Client) %s(%s) error {
+	return c.Do(ctx, %q, %q, %s, nil)
+}
+`, name, params, op.Method, op.Path, in)
+		return
+	}
+	out := g.top(outType, name+"Output")
+	fmt.Fprintf(w, `
+func (c *Client) %s(%s) (%s, error) {
+	var out %s
+	err := c.Do(ctx, %q, %q, %s, &out)
+	return out, err
+}
+`, name, params, out, out, op.Method, op.Path, in)
+}
+
+// top returns type of operation input or output, unexported struct types are named after operation.
+func (g *generator) top(t reflect.Type, name string) string {
+	if t.Kind() != reflect.Struct || (t.Name() != "" && token(t.Name()) && g.isDefined(t)) {
+		return g.typeExpr(t)
+	}
+	if existing, ok := g.names[t]; ok {
+		return existing
+	}
+	return g.define(t, g.unique(name))
+}
+
+// isDefined reports whether named type t is defined in generated source rather than imported.
+func (g *generator) isDefined(t reflect.Type) bool {
+	return !g.isImported(t) && !isRest(t)
+}
+
+func (g *generator) isImported(t reflect.Type) bool {
+	if t.PkgPath() == "" || t.PkgPath() == "main" || strings.Contains(t.PkgPath(), "/internal") {
+		return false
+	}
+	return t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+func isRest(t reflect.Type) bool {
+	return t.PkgPath() == restPkg
+}
+
+// typeExpr returns Go expression of t, named struct types are defined on first use.
+func (g *generator) typeExpr(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	if t == filePartType || t == fileHeaderType {
+		return "*" + g.imp(clientPkg) + ".File"
+	}
+	if isRest(t) {
+		switch {
+		case strings.HasPrefix(t.Name(), "Opt["):
+			get, _ := t.MethodByName("Get")
+			return g.imp(restPkg) + ".Opt[" + g.typeExpr(get.Type.Out(0)) + "]"
+		case strings.HasPrefix(t.Name(), "Patch["):
+			return g.imp("encoding/json") + ".RawMessage"
+		}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		if t.Elem() == fileHeaderType {
+			return g.typeExpr(t.Elem())
+		}
+		return "*" + g.typeExpr(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeExpr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.typeExpr(t.Elem()))
+	case reflect.Map:
+		return "map[" + g.typeExpr(t.Key()) + "]" + g.typeExpr(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+		return g.imp("encoding/json") + ".RawMessage"
+	}
+	if t.Name() == "" {
+		if t.Kind() == reflect.Struct {
+			return g.structExpr(t)
+		}
+		return t.String()
+	}
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	if g.isImported(t) {
+		return g.imp(t.PkgPath()) + "." + t.Name()
+	}
+	return g.define(t, g.unique(exportName(t.Name())))
+}
+
+// define adds definition of type t named name.
+func (g *generator) define(t reflect.Type, name string) string {
+	g.names[t] = name
+	var underlying string
+	if t.Kind() == reflect.Struct {
+		underlying = g.structExpr(t)
+	} else {
+		underlying = g.typeExpr(basicType(t))
+	}
+	fmt.Fprintf(&g.defs, "\ntype %s %s\n", name, underlying)
+	return name
+}
+
+func (g *generator) structExpr(t reflect.Type) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || skipField(field) {
+			continue
+		}
+		ft := field.Type
+		if isRest(ft) && strings.HasPrefix(ft.Name(), "Patch[") {
+			fmt.Fprintf(&b, "%s %s `patch:\"\"`\n", field.Name, g.typeExpr(ft))
+			continue
+		}
+		if field.Anonymous {
+			b.WriteString(g.typeExpr(ft))
+		} else {
+			b.WriteString(field.Name + " " + g.typeExpr(ft))
+		}
+		if field.Tag != "" {
+			fmt.Fprintf(&b, " `%s`", field.Tag)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// skipField reports whether field is bound on server side only, e.g. from context or principal.
+func skipField(field reflect.StructField) bool {
+	_, auth := field.Tag.Lookup("auth")
+	_, ctx := field.Tag.Lookup("ctx")
+	return auth || ctx
+}
+
+func hasFields(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() && !skipField(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// basicType returns predeclared type of the kind of named non-struct type t.
+func basicType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return reflect.PointerTo(t.Elem())
+	case reflect.Slice:
+		return reflect.SliceOf(t.Elem())
+	case reflect.Array:
+		return reflect.ArrayOf(t.Len(), t.Elem())
+	case reflect.Map:
+		return reflect.MapOf(t.Key(), t.Elem())
+	case reflect.Struct, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return reflect.TypeOf((*any)(nil)).Elem()
+	}
+	return reflect.TypeOf(reflect.Zero(t).Convert(basicKinds[t.Kind()]).Interface())
+}
+
+var basicKinds = map[reflect.Kind]reflect.Type{
+	reflect.Bool:       reflect.TypeOf(false),
+	reflect.Int:        reflect.TypeOf(int(0)),
+	reflect.Int8:       reflect.TypeOf(int8(0)),
+	reflect.Int16:      reflect.TypeOf(int16(0)),
+	reflect.Int32:      reflect.TypeOf(int32(0)),
+	reflect.Int64:      reflect.TypeOf(int64(0)),
+	reflect.Uint:       reflect.TypeOf(uint(0)),
+	reflect.Uint8:      reflect.TypeOf(uint8(0)),
+	reflect.Uint16:     reflect.TypeOf(uint16(0)),
+	reflect.Uint32:     reflect.TypeOf(uint32(0)),
+	reflect.Uint64:     reflect.TypeOf(uint64(0)),
+	reflect.Uintptr:    reflect.TypeOf(uintptr(0)),
+	reflect.Float32:    reflect.TypeOf(float32(0)),
+	reflect.Float64:    reflect.TypeOf(float64(0)),
+	reflect.Complex64:  reflect.TypeOf(complex64(0)),
+	reflect.Complex128: reflect.TypeOf(complex128(0)),
+	reflect.String:     reflect.TypeOf(""),
+}
+
+// unique returns name, with a number suffix if it is used already.
+func (g *generator) unique(name string) string {
+	res := name
+	for i := 2; g.used[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	g.used[res] = true
+	return res
+}
+
+// token reports whether name is an exported identifier.
+func token(name string) bool {
+	r := []rune(name)
+	return len(r) > 0 && unicode.IsUpper(r[0]) && !strings.ContainsAny(name, "[]")
+}
+
+var initialisms = map[string]string{"id": "ID", "url": "URL", "uri": "URI", "api": "API", "http": "HTTP", "json": "JSON"}
+
+// exportName converts s, e.g. `get /users/{id}` or `Page[main.User]`, to exported identifier.
+func exportName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(initialism)
+			continue
+		}
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "Op" + b.String()
+	}
+	return b.String()
+}
+
+// goIdent returns package name usable as identifier, e.g. `v4` of `echo/v4` is kept.
+func goIdent(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,126 @@
+package clientgen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/fourcels/rest/clientgen/testdata/sample"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(sample.NewService().Operations(), Config{Package: "api"})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+
+	golden := "testdata/client.golden"
+	if *update {
+		if err := os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("output differs from %s, run go test -update:\n%s", golden, src)
+	}
+}
+
+// program calls sample service with generated client.
+const program = `package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/fourcels/rest"
+	"github.com/fourcels/rest/client"
+	"github.com/fourcels/rest/clientgen/testdata/sample"
+)
+
+func main() {
+	srv := httptest.NewServer(sample.NewService())
+	defer srv.Close()
+	c := New(srv.URL + "/api")
+	ctx := context.Background()
+
+	numbers, err := c.Numbers(ctx, NumbersInput{
+		ID:     rest.NewOpt[int64](9007199254740993),
+		N:      rest.NewOpt[int64](123456789),
+		Max:    rest.NewOpt[uint64](18446744073709551615),
+		Header: rest.NewOpt[int64](-987654321098),
+		Tags:   []string{"a", "b"},
+	})
+	check(err)
+	fmt.Println(numbers)
+
+	user, err := c.CreateUser(ctx, CreateUserInput{Name: "alice", Role: "admin"})
+	check(err)
+	fmt.Println(user.ID, user.Name, user.Since.Format("2006-01-02"))
+
+	user, err = c.UpdateUser(ctx, UpdateUserInput{ID: 7, Patch: []byte(` + "`" + `{"email":"a@b.c"}` + "`" + `)})
+	check(err)
+	fmt.Println(user.ID, user.Name, user.Email)
+
+	check(c.RemoveUser(ctx, RemoveUserInput{ID: 7}))
+
+	files, err := c.Upload(ctx, UploadInput{Title: "notes", File: &client.File{Name: "a.txt", Content: strings.NewReader("hello")}})
+	check(err)
+	fmt.Println(files)
+
+	_, err = c.CreateUser(ctx, CreateUserInput{Name: "al"})
+	fmt.Println(err)
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+func TestGeneratedClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generated client is not compiled in short mode")
+	}
+	src, err := Generate(sample.NewService().Operations(), Config{Package: "main"})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	// Program is built in this module to resolve its packages.
+	dir, err := os.MkdirTemp(".", "generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range map[string][]byte{"client.go": src, "main.go": []byte(program)} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := exec.Command("go", "run", "./"+filepath.Base(dir)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, out)
+	}
+	want := `9007199254740993 123456789 18446744073709551615 -987654321098 [a b]
+1 alice admin 2024-01-02
+7 alice a@b.c
+[notes a.txt hello]
+400: Validation Error
+`
+	if got := string(out); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+// Package sample is a service of generated client tests.
+package sample
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fourcels/rest"
+	"github.com/labstack/echo/v4"
+)
+
+// User is a named type shared by operations.
+type User struct {
+	ID    int64     `json:"id"`
+	Name  string    `json:"name" minLength:"3"`
+	Email string    `json:"email,omitempty"`
+	Since time.Time `json:"since"`
+}
+
+// Role is a named non-struct type.
+type Role string
+
+// NewService returns service of sample operations.
+func NewService() *rest.Service {
+	s := rest.New(rest.WithBaseURL("/api"), rest.WithMiddleware())
+	s.WithPrincipal(func(c echo.Context) (any, error) { return "principal", nil })
+
+	s.GET("/numbers/{id}", numbers())
+	s.POST("/users", createUser())
+	s.PATCH("/users/{id}", updateUser())
+	s.DELETE("/users/{id}", deleteUser())
+	s.POST("/files", upload())
+	return s
+}
+
+func numbers() rest.Interactor {
+	type input struct {
+		ID     rest.Opt[int64]  `path:"id"`
+		N      rest.Opt[int64]  `query:"n"`
+		Max    rest.Opt[uint64] `query:"max"`
+		Header rest.Opt[int64]  `header:"X-N"`
+		Tags   []string         `query:"tags"`
+	}
+
+	return rest.NewHandler(func(c echo.Context, in input, out *string) error {
+		*out = fmt.Sprintf("%d %d %d %d %v", in.ID.Or(0), in.N.Or(0), in.Max.Or(0), in.Header.Or(0), in.Tags)
+		return nil
+	})
+}
+
+func createUser() rest.Interactor {
+	type input struct {
+		Principal string `auth:""`
+		Name      string `json:"name" minLength:"3"`
+		Role      Role   `json:"role,omitempty" enum:"admin,user"`
+	}
+
+	return rest.NewHandler(func(c echo.Context, in input, out *User) error {
+		*out = User{ID: 1, Name: in.Name + " " + string(in.Role), Since: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+		return nil
+	}, rest.WithSummary("Create user"))
+}
+
+func updateUser() rest.Interactor {
+	type input struct {
+		ID    int64 `path:"id"`
+		Patch rest.Patch[User]
+	}
+
+	return rest.NewHandler(func(c echo.Context, in input, out *User) error {
+		*out = User{ID: in.ID, Name: "alice"}
+		return in.Patch.Apply(out)
+	})
+}
+
+func deleteUser() rest.Interactor {
+	type input struct {
+		ID int64 `path:"id"`
+	}
+
+	return rest.NewHandler(func(c echo.Context, in input, out *rest.NoContent) error {
+		return nil
+	}, rest.WithOperationID("removeUser"))
+}
+
+func upload() rest.Interactor {
+	type input struct {
+		Title string        `formData:"title"`
+		File  rest.FilePart `formData:"file"`
+	}
+
+	return rest.NewHandler(func(c echo.Context, in input, out *[]string) error {
+		content, err := io.ReadAll(in.File)
+		*out = []string{in.Title, in.File.FileName(), strings.TrimSpace(string(content))}
+		return err
+	})
+}
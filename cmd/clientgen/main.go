@@ -0,0 +1,95 @@
+// Command clientgen generates typed Go client of operations registered on rest.Service.
+//
+// Service is created by an exported function of a non-main package returning *rest.Service,
+// it is run by a temporary program in current module.
+//
+//	clientgen -service example.com/app/api.NewService -o client/client.go -package client
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var program = template.Must(template.New("main").Parse(`package main
+
+import (
+	"log"
+
+	"github.com/fourcels/rest/clientgen"
+	service {{printf "%q" .Package}}
+)
+
+func main() {
+	if err := clientgen.WriteFile({{printf "%q" .Output}}, service.{{.Func}}().Operations(), clientgen.Config{Package: {{printf "%q" .Name}}}); err != nil {
+		log.Fatal(err)
+	}
+}
+`))
+
+func main() {
+	service := flag.String("service", "", "function returning *rest.Service, e.g. example.com/app/api.NewService")
+	out := flag.String("o", "client.go", "output file")
+	name := flag.String("package", "client", "package name of generated client")
+	flag.Parse()
+
+	pkg, fn, err := splitFunc(*service)
+	if err != nil {
+		log.Fatal(err)
+	}
+	output, err := filepath.Abs(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := run(pkg, fn, output, *name); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			// Program has reported the error already.
+			os.Exit(exit.ExitCode())
+		}
+		log.Fatal(err)
+	}
+}
+
+// splitFunc splits qualified function name into import path and function name.
+func splitFunc(service string) (string, string, error) {
+	i := strings.LastIndex(service, ".")
+	if i < 0 || i < strings.LastIndex(service, "/") || i == len(service)-1 {
+		return "", "", fmt.Errorf("-service %q is not a qualified function name, e.g. example.com/app/api.NewService", service)
+	}
+	return service[:i], service[i+1:], nil
+}
+
+// run generates client by program importing pkg, it is run in current module to resolve pkg.
+func run(pkg, fn, output, name string) error {
+	var src bytes.Buffer
+	err := program.Execute(&src, map[string]string{"Package": pkg, "Func": fn, "Output": output, "Name": name})
+	if err != nil {
+		return err
+	}
+	code, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp(".", "clientgen")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), code, 0o644); err != nil {
+		return err
+	}
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,34 @@
+// Command restmock serves examples of every operation of OpenAPI spec, e.g. to start frontend
+// work before handlers exist.
+//
+//	restmock -spec openapi.json -addr :1323
+//
+// Status and named example are selected with Prefer header, e.g. `Prefer: code=404, example=missing`.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/fourcels/rest"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "OpenAPI spec file")
+	addr := flag.String("addr", ":1323", "listen address")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	spec := &openapi3.Spec{}
+	if err := spec.UnmarshalJSON(data); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("mock server listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, rest.MockHandler(spec)))
+}
@@ -0,0 +1,69 @@
+// Command specdiff compares OpenAPI spec against a baseline and fails on breaking changes,
+// unless major version of spec info is bumped.
+//
+//	specdiff -base openapi.base.json -current http://localhost:1323/api/docs/openapi.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fourcels/rest/specdiff"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+func main() {
+	basePath := flag.String("base", "openapi.base.json", "baseline OpenAPI spec file or URL")
+	currentPath := flag.String("current", "openapi.json", "current OpenAPI spec file or URL")
+	flag.Parse()
+
+	base, err := load(*basePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	current, err := load(*currentPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report := specdiff.Compare(base, current)
+	fmt.Print(report)
+	if len(report.Breaking) > 0 {
+		if specdiff.MajorVersionChanged(base, current) {
+			fmt.Printf("Breaking changes are allowed by version bump %s -> %s\n", base.Info.Version, current.Info.Version)
+			return
+		}
+		os.Exit(1)
+	}
+}
+
+func load(path string) (*openapi3.Spec, error) {
+	data, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &openapi3.Spec{}
+	if err := spec.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+func read(path string) ([]byte, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.ReadFile(path)
+	}
+	res, err := http.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", path, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
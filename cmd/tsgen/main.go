@@ -0,0 +1,58 @@
+// Command tsgen generates TypeScript client of OpenAPI spec served by Service.Docs.
+//
+//	tsgen -spec http://localhost:1323/api/docs/openapi.json -o web/src/api.ts
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fourcels/rest/tsgen"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "OpenAPI spec file or URL")
+	out := flag.String("o", "api.ts", "output file, - for stdout")
+	flag.Parse()
+
+	data, err := read(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	spec := &openapi3.Spec{}
+	if err := spec.UnmarshalJSON(data); err != nil {
+		log.Fatal(err)
+	}
+	if *out == "-" {
+		src, err := tsgen.Generate(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(src)
+		return
+	}
+	if err := tsgen.WriteFile(*out, spec); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func read(path string) ([]byte, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.ReadFile(path)
+	}
+	res, err := http.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", path, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
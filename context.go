@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type scopeCtxKey struct{}
+
+// requestScope holds request values reachable from context.Context of use cases.
+type requestScope struct {
+	principal func() (any, error)
+	requestID string
+	header    http.Header
+}
+
+func newRequestScope(c echo.Context, b *CustomBinder) *requestScope {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	if requestID == "" {
+		requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+	}
+	return &requestScope{
+		principal: func() (any, error) { return b.principal(c) },
+		requestID: requestID,
+		header:    c.Response().Header(),
+	}
+}
+
+func withRequestScope(ctx context.Context, scope *requestScope) context.Context {
+	return context.WithValue(ctx, scopeCtxKey{}, scope)
+}
+
+func scopeFrom(ctx context.Context) *requestScope {
+	if scope, ok := ctx.Value(scopeCtxKey{}).(*requestScope); ok {
+		return scope
+	}
+	return &requestScope{header: http.Header{}}
+}
+
+// Principal returns authenticated principal of request, see Service.WithPrincipal.
+func Principal[T any](ctx context.Context) (T, bool) {
+	var res T
+	scope := scopeFrom(ctx)
+	if scope.principal == nil {
+		return res, false
+	}
+	principal, err := scope.principal()
+	if err != nil {
+		return res, false
+	}
+	res, ok := principal.(T)
+	return res, ok
+}
+
+// RequestID returns X-Request-ID of request.
+func RequestID(ctx context.Context) string {
+	return scopeFrom(ctx).requestID
+}
+
+// ResponseHeader returns header of response, it is a throwaway header outside of request.
+func ResponseHeader(ctx context.Context) http.Header {
+	return scopeFrom(ctx).header
+}
+
+// ContextWithPrincipal returns a copy of ctx with principal, e.g. to call use cases in tests.
+func ContextWithPrincipal(ctx context.Context, principal any) context.Context {
+	scope := *scopeFrom(ctx)
+	scope.principal = func() (any, error) { return principal, nil }
+	return withRequestScope(ctx, &scope)
+}
+
+// ContextWithRequestID returns a copy of ctx with request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	scope := *scopeFrom(ctx)
+	scope.requestID = requestID
+	return withRequestScope(ctx, &scope)
+}
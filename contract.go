@@ -0,0 +1,337 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	gojsonschema "github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// ContractError describes response of operation that does not match its documented responses.
+type ContractError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Violations []string
+}
+
+// Error implements error.
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("%s %s responded %d against spec: %s", e.Method, e.Path, e.StatusCode, strings.Join(e.Violations, "; "))
+}
+
+type contractReportKey struct{}
+
+// WithContractReport returns ctx in which violations of responses to requests are passed to
+// report instead of report of ValidateResponses, e.g. to report them to the test sending requests.
+func WithContractReport(ctx context.Context, report func(c echo.Context, err *ContractError) error) context.Context {
+	return context.WithValue(ctx, contractReportKey{}, report)
+}
+
+// contract validates responses of operations against spec, compiled schemas are cached.
+type contract struct {
+	report  func(c echo.Context, err *ContractError) error
+	mu      sync.Mutex
+	schemas map[string]*gojsonschema.Schema
+}
+
+// ValidateResponses enables validation of status code, headers and body written by Interactors
+// against documented responses of operation, it is meant for development and tests.
+// Violations are passed to report, response is replaced with error if report returns one.
+// Violations are logged if report is omitted, see WithContractReport for requests with own report.
+func (s *Service) ValidateResponses(report ...func(c echo.Context, err *ContractError) error) {
+	ct := &contract{schemas: map[string]*gojsonschema.Schema{}}
+	if len(report) > 0 {
+		ct.report = report[0]
+	} else {
+		ct.report = func(c echo.Context, err *ContractError) error {
+			s.document.logger.Warn("response violates spec", "operation", err.Method+" "+err.Path,
+				"status", err.StatusCode, "violations", err.Violations)
+			return nil
+		}
+	}
+	s.contract.Store(ct)
+}
+
+// verify validates responses of op once validation is enabled.
+func (s *Service) verify(op *operation) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ct := s.contract.Load()
+			if ct == nil {
+				return next(c)
+			}
+			return ct.serve(c, next, op.document, op)
+		}
+	}
+}
+
+// bufferedWriter holds response until it is validated.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (ct *contract) serve(c echo.Context, next echo.HandlerFunc, d *document, op *operation) error {
+	res := c.Response()
+	before := map[string]bool{}
+	for name := range res.Header() {
+		before[name] = true
+	}
+	writer := res.Writer
+	buffered := &bufferedWriter{ResponseWriter: writer, status: http.StatusOK}
+	res.Writer = buffered
+	err := next(c)
+	if err != nil {
+		customHTTPErrorHandler(err, c)
+	}
+	res.Writer = writer
+
+	// Errors of binding and validation are not written by Interactor.
+	phase, _ := c.Get(phaseKey).(string)
+	if phase != phaseBind && phase != phaseValidate {
+		violations := ct.validate(d, op, buffered.status, res.Header(), before, buffered.body.Bytes())
+		if len(violations) > 0 {
+			cerr := &ContractError{Method: op.method, Path: op.path, StatusCode: buffered.status, Violations: violations}
+			report := ct.report
+			if r, ok := c.Request().Context().Value(contractReportKey{}).(func(echo.Context, *ContractError) error); ok {
+				report = r
+			}
+			if rerr := report(c, cerr); rerr != nil {
+				res.Committed = false
+				res.Size = 0
+				res.Header().Del(echo.HeaderContentLength)
+				buffered.body.Reset()
+				res.Writer = buffered
+				customHTTPErrorHandler(rerr, c)
+				res.Writer = writer
+			}
+		}
+	}
+
+	writer.WriteHeader(buffered.status)
+	if _, werr := writer.Write(buffered.body.Bytes()); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// validate returns violations of response against documented responses of operation.
+func (ct *contract) validate(d *document, op *operation, status int, header http.Header, before map[string]bool, body []byte) []string {
+	var violations []string
+	responses := op.spec().Responses
+	key, resp := documentedResponse(responses, status)
+	if resp == nil {
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+
+	var names []string
+	for name := range resp.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	documented := map[string]bool{}
+	for _, name := range names {
+		documented[http.CanonicalHeaderKey(name)] = true
+		h := resp.Headers[name].Header
+		if h == nil {
+			continue
+		}
+		value := header.Get(name)
+		if value == "" {
+			if h.Required != nil && *h.Required {
+				violations = append(violations, fmt.Sprintf("header %s is missing", name))
+			}
+			continue
+		}
+		if h.Schema != nil && h.Schema.Schema != nil && !headerMatches(h.Schema.Schema, value) {
+			violations = append(violations, fmt.Sprintf("header %s: %q does not match schema", name, value))
+		}
+	}
+	var undocumented []string
+	for name := range header {
+		switch name {
+		case echo.HeaderContentType, echo.HeaderContentLength, echo.HeaderSetCookie:
+			continue
+		case HeaderPrefer:
+			// Status and example applied by mock, see WithMock.
+			continue
+		}
+		if !before[name] && !documented[name] {
+			undocumented = append(undocumented, name)
+		}
+	}
+	sort.Strings(undocumented)
+	for _, name := range undocumented {
+		violations = append(violations, fmt.Sprintf("header %s is not documented", name))
+	}
+
+	if len(body) == 0 {
+		if len(resp.Content) > 0 && status != http.StatusNoContent {
+			violations = append(violations, "body is missing")
+		}
+		return violations
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get(echo.HeaderContentType))
+	content, ok := resp.Content[mediaType]
+	if !ok {
+		return append(violations, fmt.Sprintf("content type %q is not documented", mediaType))
+	}
+	if content.Schema == nil || !strings.Contains(mediaType, "json") {
+		return violations
+	}
+	schema, err := ct.compile(d, fmt.Sprintf("%s %s %s %s %s", d.version, op.method, op.path, key, mediaType), content.Schema)
+	if err != nil {
+		return append(violations, "schema: "+err.Error())
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return append(violations, "body: "+err.Error())
+	}
+	if err := schema.Validate(v); err != nil {
+		if verr, ok := err.(*gojsonschema.ValidationError); ok {
+			for _, leaf := range validationLeaves(verr) {
+				violations = append(violations, fmt.Sprintf("body%s: %s", leaf.InstanceLocation, leaf.Message))
+			}
+		} else {
+			violations = append(violations, "body: "+err.Error())
+		}
+	}
+	return violations
+}
+
+// documentedResponse finds response of status, e.g. `201`, `2XX` or `default`.
+func documentedResponse(responses openapi3.Responses, status int) (string, *openapi3.Response) {
+	for _, key := range []string{strconv.Itoa(status), fmt.Sprintf("%dXX", status/100)} {
+		if r, ok := responses.MapOfResponseOrRefValues[key]; ok && r.Response != nil {
+			return key, r.Response
+		}
+	}
+	if responses.Default != nil && responses.Default.Response != nil {
+		return "default", responses.Default.Response
+	}
+	return "", nil
+}
+
+func headerMatches(schema *openapi3.Schema, value string) bool {
+	if schema.Type == nil {
+		return true
+	}
+	var err error
+	switch *schema.Type {
+	case openapi3.SchemaTypeInteger:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case openapi3.SchemaTypeNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case openapi3.SchemaTypeBoolean:
+		_, err = strconv.ParseBool(value)
+	}
+	return err == nil
+}
+
+// compile compiles OpenAPI schema with components of spec into JSON schema.
+func (ct *contract) compile(d *document, key string, s *openapi3.SchemaOrRef) (*gojsonschema.Schema, error) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if schema, ok := ct.schemas[key]; ok {
+		return schema, nil
+	}
+	var components any = map[string]any{}
+	if d.OpenAPI.Components != nil {
+		components = d.OpenAPI.Components
+	}
+	doc, err := json.Marshal(map[string]any{"components": components, "schema": s})
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return nil, err
+	}
+	doc, err = json.Marshal(jsonSchemaNullable(v))
+	if err != nil {
+		return nil, err
+	}
+	compiler := gojsonschema.NewCompiler()
+	compiler.Draft = gojsonschema.Draft4
+	if err := compiler.AddResource("response.json", bytes.NewReader(doc)); err != nil {
+		return nil, err
+	}
+	schema, err := compiler.Compile("response.json#/schema")
+	if err != nil {
+		return nil, err
+	}
+	ct.schemas[key] = schema
+	return schema, nil
+}
+
+// jsonSchemaNullable converts OpenAPI `nullable` to null type of JSON schema.
+func jsonSchemaNullable(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = jsonSchemaNullable(item)
+		}
+		nullable, ok := v["nullable"].(bool)
+		if !ok {
+			break
+		}
+		delete(v, "nullable")
+		if !nullable {
+			break
+		}
+		t, typed := v["type"].(string)
+		if typed {
+			v["type"] = []any{t, "null"}
+		}
+		if enum, ok := v["enum"].([]any); ok {
+			v["enum"] = append(enum, nil)
+		}
+		if _, ok := v["allOf"]; ok && !typed {
+			// Nullable reference, e.g. of Opt, is documented in allOf.
+			schema := map[string]any{}
+			for k, item := range v {
+				schema[k] = item
+				delete(v, k)
+			}
+			v["anyOf"] = []any{map[string]any{"type": "null"}, schema}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = jsonSchemaNullable(item)
+		}
+	}
+	return v
+}
+
+func validationLeaves(err *gojsonschema.ValidationError) []*gojsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*gojsonschema.ValidationError{err}
+	}
+	var res []*gojsonschema.ValidationError
+	for _, cause := range err.Causes {
+		res = append(res, validationLeaves(cause)...)
+	}
+	return res
+}
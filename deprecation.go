@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// Headers of deprecated operations, see WithDeprecated.
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// deprecation of operation.
+type deprecation struct {
+	// date operation is deprecated since, zero if unknown.
+	date        time.Time
+	sunset      time.Time
+	replacement string
+	logger      *slog.Logger
+}
+
+// WithDeprecated marks operation deprecated, it is removed after sunset, zero if unknown, in
+// favor of replacement, e.g. `/api/v2/users` or `GET /v2/users`. Responses carry Sunset header
+// and a link to replacement path or URL, and every call is logged. Deprecation header is sent
+// only with date of WithDeprecationDate.
+func WithDeprecated(sunset time.Time, replacement string) option {
+	return operationOption(func(op *operation) {
+		dp := op.deprecate()
+		dp.sunset, dp.replacement = sunset, replacement
+		if !sunset.IsZero() {
+			setExtension(op.spec(), "x-sunset", sunset.UTC().Format(time.DateOnly))
+		}
+	})
+}
+
+// WithDeprecationDate marks operation deprecated since date, sent in Deprecation header of
+// responses as of RFC 9745, e.g. `@1735689600`.
+func WithDeprecationDate(date time.Time) option {
+	return operationOption(func(op *operation) {
+		op.deprecate().date = date
+	})
+}
+
+// deprecate marks op deprecated and returns its deprecation.
+func (op *operation) deprecate() *deprecation {
+	if op.deprecation == nil {
+		op.deprecation = &deprecation{}
+	}
+	op.SetIsDeprecated(true)
+	return op.deprecation
+}
+
+// describeDeprecation appends deprecation note to description of operation, it is called after
+// all options so the note is kept by WithDescription.
+func (d *document) describeDeprecation(op *operation) {
+	dp := op.deprecation
+	if dp == nil {
+		return
+	}
+	var note []string
+	if !dp.date.IsZero() {
+		note = append(note, "Deprecated since "+dp.date.UTC().Format(time.DateOnly)+".")
+	} else {
+		note = append(note, "Deprecated.")
+	}
+	if !dp.sunset.IsZero() {
+		note = append(note, "Sunset on "+dp.sunset.UTC().Format(time.DateOnly)+".")
+	}
+	if dp.replacement != "" {
+		note = append(note, "Use `"+dp.replacement+"` instead.")
+	}
+	if len(note) == 1 && dp.date.IsZero() {
+		return
+	}
+	description := strings.Join(note, " ")
+	if spec := op.spec(); spec.Description != nil && *spec.Description != "" {
+		description = *spec.Description + "\n\n" + description
+	}
+	op.SetDescription(description)
+}
+
+// link returns Link header value of replacement, it is empty if replacement is not a path or URL.
+func (dp *deprecation) link() string {
+	if !strings.HasPrefix(dp.replacement, "/") && !strings.HasPrefix(dp.replacement, "http://") &&
+		!strings.HasPrefix(dp.replacement, "https://") {
+		return ""
+	}
+	return "<" + dp.replacement + `>; rel="successor-version"`
+}
+
+// apply sets deprecation headers of response and logs the call.
+func (dp *deprecation) apply(c echo.Context, op *operation) {
+	header := c.Response().Header()
+	if !dp.date.IsZero() {
+		header.Set(HeaderDeprecation, "@"+strconv.FormatInt(dp.date.Unix(), 10))
+	}
+	if !dp.sunset.IsZero() {
+		header.Set(HeaderSunset, dp.sunset.UTC().Format(http.TimeFormat))
+	}
+	if link := dp.link(); link != "" {
+		header.Add(HeaderLink, link)
+	}
+	dp.logger.Warn("deprecated operation called", "operation", op.id(), "method", op.method, "path", op.path,
+		"sunset", dp.sunset, "replacement", dp.replacement, "user_agent", c.Request().UserAgent())
+}
+
+// documentDeprecation adds deprecation headers to documented responses of operation.
+func (d *document) documentDeprecation(op *operation) {
+	dp := op.deprecation
+	if dp == nil {
+		return
+	}
+	dp.logger = d.logger
+	headers := map[string]string{}
+	if !dp.date.IsZero() {
+		headers[HeaderDeprecation] = "Date operation is deprecated since, `@` followed by Unix time."
+	}
+	if !dp.sunset.IsZero() {
+		headers[HeaderSunset] = "Date after which operation is removed."
+	}
+	if dp.link() != "" {
+		headers[HeaderLink] = "Replacement of operation."
+	}
+	for _, res := range op.spec().Responses.MapOfResponseOrRefValues {
+		if res.Response == nil {
+			continue
+		}
+		if res.Response.Headers == nil {
+			res.Response.Headers = map[string]openapi3.HeaderOrRef{}
+		}
+		for name, description := range headers {
+			description := description
+			res.Response.Headers[name] = openapi3.HeaderOrRef{Header: &openapi3.Header{
+				Description: &description,
+				Schema:      &openapi3.SchemaOrRef{Schema: (&openapi3.Schema{}).WithType(openapi3.SchemaTypeString)},
+			}}
+		}
+	}
+}
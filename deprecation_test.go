@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestDeprecationHeaders(t *testing.T) {
+	sunset := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := func(c echo.Context, in struct{}, out *[]string) error { return nil }
+	s := New(WithMiddleware())
+	s.GET("/v1/users", NewHandler(list, WithOperationID("listUsersV1"), WithDeprecated(sunset, "/v2/users")))
+	s.GET("/v1/groups", NewHandler(list, WithOperationID("listGroupsV1"), WithDeprecated(sunset, "/v2/groups"),
+		WithDeprecationDate(date)))
+
+	for _, tt := range []struct {
+		path, deprecation, link string
+	}{
+		{"/v1/users", "", `</v2/users>; rel="successor-version"`},
+		{"/v1/groups", "@1735689600", `</v2/groups>; rel="successor-version"`},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			header := rec.Header()
+			if got := header.Values(HeaderDeprecation); tt.deprecation == "" && len(got) > 0 ||
+				tt.deprecation != "" && header.Get(HeaderDeprecation) != tt.deprecation {
+				t.Errorf("got Deprecation %q, want %q", got, tt.deprecation)
+			}
+			if got := header.Get(HeaderSunset); got != "Sun, 01 Jun 2025 00:00:00 GMT" {
+				t.Errorf("got Sunset %q", got)
+			}
+			if got := header.Get(HeaderLink); got != tt.link {
+				t.Errorf("got Link %q, want %q", got, tt.link)
+			}
+
+			headers := s.OpenAPI.Paths.MapOfPathItemValues[tt.path].MapOfOperationValues["get"].
+				Responses.MapOfResponseOrRefValues["200"].Response.Headers
+			if _, ok := headers[HeaderDeprecation]; ok != (tt.deprecation != "") {
+				t.Errorf("got Deprecation documented %v", ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/openapi-go"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// document builds OpenAPI spec of registered Interactors, it is shared by Service and Mux.
+type document struct {
+	reflector *openapi3.Reflector
+	OpenAPI   *openapi3.Spec
+	logger    *slog.Logger
+	// operations are kept in order of registration.
+	operations []*operation
+	// ids are operationIds in use, defNames are component names of input and output types.
+	ids      map[string]bool
+	defNames map[reflect.Type]string
+	// version of document among ordered versions, see WithVersions.
+	version  string
+	versions []string
+}
+
+func newDocument(baseUrl string) *document {
+	d := &document{logger: slog.Default(), ids: map[string]bool{}, defNames: map[reflect.Type]string{}}
+	d.reflector = &openapi3.Reflector{}
+	d.reflector.DefaultOptions = append(d.reflector.DefaultOptions, jsonschema.InterceptDefName(d.defName))
+	d.reflector.JSONSchemaReflector().InlineDefinition(FilePart{})
+	d.OpenAPI = &openapi3.Spec{Openapi: "3.0.3"}
+	if baseUrl != "" {
+		d.OpenAPI.WithServers(openapi3.Server{
+			URL: baseUrl,
+		})
+	}
+	d.reflector.Spec = d.OpenAPI
+	return d
+}
+
+// addOperation documents Interactor h and returns its operation, nil if it is not in version of document.
+func (d *document) addOperation(method, path string, h Interactor, ops []option) *operation {
+	oc, err := d.reflector.NewOperationContext(method, path)
+	if err != nil {
+		d.logger.Error("add operation", "method", method, "path", path, "error", err)
+	}
+
+	op := &operation{OperationContext: oc, method: method, path: path, interactor: h, document: d}
+	op.SetSummary(h.Summary())
+
+	for _, o := range append(ops, h.Options()...) {
+		o(op)
+	}
+	if !d.includes(op) {
+		return nil
+	}
+	d.identify(op, h)
+
+	op.AddReqStructure(h.Input())
+	op.AddRespStructure(h.Output())
+	if p, ok := patchField(reflect.ValueOf(h.Input()).Elem()); ok {
+		if err := d.documentPatch(op, p.patchTarget()); err != nil {
+			d.logger.Error("add operation", "method", method, "path", path, "error", err)
+		}
+		if err := compilePatch(reflect.TypeOf(p.patchTarget()).Elem()); err != nil {
+			panic(fmt.Sprintf("rest: %s %s: %v", method, path, err))
+		}
+	}
+	d.documentLimits(op)
+	d.describeDeprecation(op)
+
+	if err := d.reflector.AddOperation(oc); err != nil {
+		d.logger.Error("add operation", "method", method, "path", path, "error", err)
+	}
+	d.documentFilePart(op)
+	d.documentOpt(op)
+	d.documentCookies(op)
+	d.documentExamples(op)
+	d.documentDeprecation(op)
+	d.operations = append(d.operations, op)
+	return op
+}
+
+// Operations returns registered Interactors in order of registration, e.g. to generate clients.
+func (d *document) Operations() []Operation {
+	res := make([]Operation, 0, len(d.operations))
+	for _, op := range d.operations {
+		res = append(res, op.describe())
+	}
+	return res
+}
+
+// reflectSchema reflects v into OpenAPI schema, collecting definitions into spec components.
+func (d *document) reflectSchema(v any) (openapi3.SchemaOrRef, error) {
+	res := openapi3.SchemaOrRef{}
+	schema, err := d.reflector.Reflect(v,
+		jsonschema.RootRef,
+		jsonschema.DefinitionsPrefix("#/components/schemas/"),
+		jsonschema.CollectDefinitions(func(name string, schema jsonschema.Schema) {
+			schemas := d.reflector.SpecEns().ComponentsEns().SchemasEns()
+			if _, ok := schemas.MapOfSchemaOrRefValues[name]; ok {
+				return
+			}
+			s := openapi3.SchemaOrRef{}
+			s.FromJSONSchema(schema.ToSchemaOrBool())
+			schemas.WithMapOfSchemaOrRefValuesItem(name, s)
+		}),
+	)
+	if err != nil {
+		return res, err
+	}
+	res.FromJSONSchema(schema.ToSchemaOrBool())
+	return res, nil
+}
+
+// documentPatch documents JSON Merge Patch and JSON Patch request bodies of a Patch field.
+func (d *document) documentPatch(op *operation, target any) error {
+	merge, err := d.reflectSchema(target)
+	if err != nil {
+		return err
+	}
+	ops, err := d.reflectSchema(new([]PatchOperation))
+	if err != nil {
+		return err
+	}
+	body := op.spec().RequestBodyEns().RequestBodyEns()
+	body.WithContentItem(MIMEApplicationMergePatchJSON, openapi3.MediaType{Schema: &merge})
+	body.WithContentItem(MIMEApplicationJSONPatchJSON, openapi3.MediaType{Schema: &ops})
+	return nil
+}
+
+// documentLimits documents body size limit and timeouts of operation with vendor extensions.
+func (d *document) documentLimits(op *operation) {
+	o := op.spec()
+	if op.maxBodySize > 0 {
+		o.WithMapOfAnythingItem("x-max-body-size", op.maxBodySize)
+		op.AddRespStructure(new(ErrResponse), openapi.WithHTTPStatus(http.StatusRequestEntityTooLarge))
+	}
+	if op.readTimeout > 0 {
+		o.WithMapOfAnythingItem("x-read-timeout", op.readTimeout.String())
+	}
+	if op.handlerTimeout > 0 {
+		o.WithMapOfAnythingItem("x-handler-timeout", op.handlerTimeout.String())
+		op.AddRespStructure(new(ErrResponse), openapi.WithHTTPStatus(http.StatusGatewayTimeout))
+	}
+}
+
+// documentCookies documents `cookie` fields of responses as Set-Cookie header, with cookie
+// attributes in its description, example and `x-cookies` extension.
+func (d *document) documentCookies(op *operation) {
+	for _, cu := range op.Response() {
+		if cu.Structure == nil {
+			continue
+		}
+		typ := reflect.TypeOf(cu.Structure)
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			continue
+		}
+		var notes []string
+		var example any
+		cookies := map[string]any{}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag := field.Tag.Get("cookie")
+			if field.Anonymous || tag == "" {
+				continue
+			}
+			name, attrs := parseCookieTag(tag)
+			schema, err := d.reflectSchema(reflect.Zero(field.Type).Interface())
+			if err != nil {
+				d.logger.Error("add operation", "method", op.method, "path", op.path, "error", err)
+				continue
+			}
+			cookie := map[string]any{"schema": schema}
+			for _, attr := range attrs {
+				if k, v, ok := strings.Cut(attr, "="); ok {
+					cookie[strings.ToLower(k)] = v
+				} else {
+					cookie[strings.ToLower(k)] = true
+				}
+			}
+			cookies[name] = cookie
+			note := "`" + name + "`"
+			if len(attrs) > 0 {
+				note += " (" + strings.Join(attrs, "; ") + ")"
+			}
+			notes = append(notes, note)
+			if example == nil {
+				example = strings.Join(append([]string{name + "=value"}, attrs...), "; ")
+			}
+		}
+		if len(cookies) == 0 {
+			continue
+		}
+		key := responseKey(cu)
+		res, ok := op.spec().Responses.MapOfResponseOrRefValues[key]
+		if !ok || res.Response == nil {
+			continue
+		}
+		if res.Response.Headers == nil {
+			res.Response.Headers = map[string]openapi3.HeaderOrRef{}
+		}
+		description := "Sets cookies " + strings.Join(notes, ", ") + "."
+		res.Response.Headers[echo.HeaderSetCookie] = openapi3.HeaderOrRef{Header: &openapi3.Header{
+			Description:   &description,
+			Schema:        &openapi3.SchemaOrRef{Schema: (&openapi3.Schema{}).WithType(openapi3.SchemaTypeString)},
+			Example:       &example,
+			MapOfAnything: map[string]any{"x-cookies": cookies},
+		}}
+	}
+}
+
+// responseKey returns documented status of content unit, e.g. `200`, `4XX` or `default`.
+func responseKey(cu openapi.ContentUnit) string {
+	switch {
+	case cu.IsDefault:
+		return "default"
+	case cu.HTTPStatus == 0:
+		return strconv.Itoa(http.StatusOK)
+	case cu.HTTPStatus < 6:
+		return strconv.Itoa(cu.HTTPStatus) + "XX"
+	}
+	return strconv.Itoa(cu.HTTPStatus)
+}
+
+// specHandler serves OpenAPI spec as JSON.
+func (d *document) specHandler(w http.ResponseWriter, r *http.Request) {
+	writeSpec(w, d.OpenAPI)
+}
+
+func writeSpec(w http.ResponseWriter, spec *openapi3.Spec) {
+	schema, err := spec.MarshalJSON()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	w.WriteHeader(http.StatusOK)
+	w.Write(schema)
+}
+
+// uiHandler serves Swagger UI for spec at specURL.
+func (d *document) uiHandler(specURL string, config ...map[string]any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t := template.Must(template.New("swagger").Parse(swagger))
+		var html bytes.Buffer
+		var setting map[string]any
+		if len(config) > 0 {
+			setting = config[0]
+		}
+		t.Execute(&html, map[string]any{
+			"AssetBase":   "https://unpkg.com/swagger-ui-dist",
+			"SwaggerJson": specURL,
+			"Setting":     setting,
+		})
+		w.Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		w.WriteHeader(http.StatusOK)
+		w.Write(html.Bytes())
+	}
+}
+
+func (d *document) WithSecurity(key string, securityScheme *openapi3.SecurityScheme) {
+	d.OpenAPI.ComponentsEns().SecuritySchemesEns().WithMapOfSecuritySchemeOrRefValuesItem(key,
+		openapi3.SecuritySchemeOrRef{
+			SecurityScheme: securityScheme,
+		})
+}
+
+func (d *document) WithHttpBearerSecurity(key string) {
+	d.WithHttpSecurity(key, SchemeBearer)
+}
+func (d *document) WithHttpBasicSecurity(key string) {
+	d.WithHttpSecurity(key, SchemeBasic)
+}
+func (d *document) WithHttpSecurity(key string, scheme Scheme) {
+	d.WithSecurity(key, &openapi3.SecurityScheme{
+		HTTPSecurityScheme: &openapi3.HTTPSecurityScheme{
+			Scheme: string(scheme),
+		},
+	})
+}
+func (d *document) WithAPIKeySecurity(key, name string, in openapi3.APIKeySecuritySchemeIn) {
+	d.WithSecurity(key, &openapi3.SecurityScheme{
+		APIKeySecurityScheme: &openapi3.APIKeySecurityScheme{
+			In:   in,
+			Name: name,
+		},
+	})
+}
+
+func (d *document) WithTags(val ...string) {
+	tags := make([]openapi3.Tag, 0)
+	for _, v := range val {
+		tags = append(tags, openapi3.Tag{Name: v})
+	}
+	d.OpenAPI.WithTags(tags...)
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -57,7 +58,8 @@ func Err(err error) (int, ErrResponse) {
 	er.ErrorText = err.Error()
 	er.httpStatusCode = http.StatusInternalServerError
 
-	if he, ok := err.(*echo.HTTPError); ok {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
 		if he.Internal != nil {
 			if herr, ok := he.Internal.(*echo.HTTPError); ok {
 				he = herr
@@ -68,6 +70,14 @@ func Err(err error) (int, ErrResponse) {
 			er.ErrorText = m
 		}
 	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		er.httpStatusCode = http.StatusRequestEntityTooLarge
+	}
+	// Deadline of WithHandlerTimeout is exceeded, the service is available but too slow.
+	if errors.Is(err, context.DeadlineExceeded) {
+		er.httpStatusCode = http.StatusGatewayTimeout
+	}
 	if errors.As(err, &withHTTPStatus) {
 		er.httpStatusCode = withHTTPStatus.HTTPStatus()
 	}
@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestErrDeadlineExceeded(t *testing.T) {
+	if code, _ := Err(fmt.Errorf("query users: %w", context.DeadlineExceeded)); code != http.StatusGatewayTimeout {
+		t.Errorf("got %d, want %d", code, http.StatusGatewayTimeout)
+	}
+
+	s := New(WithMiddleware())
+	s.GET("/slow", NewHandler(func(c echo.Context, in struct{}, out *string) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	}, WithHandlerTimeout(10*time.Millisecond)))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("got %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	if responses := s.Operations()[0].Responses; !slices.Contains(responses, "504") {
+		t.Errorf("504 is not documented: %q", responses)
+	}
+}
+
+func TestErrWrappedHTTPError(t *testing.T) {
+	code, res := Err(fmt.Errorf("load user: %w", echo.NewHTTPError(http.StatusNotFound, "user not found")))
+	if code != http.StatusNotFound || res.ErrorText != "user not found" {
+		t.Errorf("got %d %q, want 404 user not found", code, res.ErrorText)
+	}
+}
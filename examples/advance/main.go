@@ -19,6 +19,13 @@ func main() {
 	s.OpenAPI.Info.WithTitle("Advance Example")
 	s.WithTags("Login", "Empty")
 	s.WithHttpBearerSecurity("bearerAuth")
+	s.WithPrincipal(func(c echo.Context) (any, error) {
+		token, ok := c.Get("user").(*jwt.Token)
+		if !ok {
+			return nil, nil
+		}
+		return token.Claims, nil
+	})
 
 	s.POST("/login", login())
 	s.POST("/upload", upload())
@@ -26,7 +33,12 @@ func main() {
 
 	admin := s.Group("/admin", rest.WithTags("Admin"), rest.WithSecurity("bearerAuth"))
 
-	admin.Use(echojwt.JWT(signingKey))
+	admin.Use(echojwt.WithConfig(echojwt.Config{
+		SigningKey: signingKey,
+		NewClaimsFunc: func(c echo.Context) jwt.Claims {
+			return new(jwtCustomClaims)
+		},
+	}))
 	admin.GET("/hello", hello())
 
 	// Swagger UI endpoint at /docs.
@@ -49,13 +61,13 @@ func login() rest.Interactor {
 	// Declare input port type.
 	type input struct {
 		Username  string `json:"username ,omitempty" minLength:"3"`
-		Password  string `json:"password,omitempty" minLength:"3" default:"a12345"`
+		Password  string `json:"password,omitempty" minLength:"3" default:"a12345" sensitive:"true"`
 		Username2 string `json:"username2,omitempty"`
 	}
 
 	// Declare output port type.
 	type output struct {
-		Token string `json:"token"`
+		Token string `json:"token" sensitive:""`
 	}
 	// jwtCustomClaims are custom claims extending default ones.
 	// See https://github.com/golang-jwt/jwt for more examples
@@ -92,11 +104,13 @@ func login() rest.Interactor {
 }
 
 func hello() rest.Interactor {
-	return rest.NewHandler(func(c echo.Context, in struct{}, out *string) error {
-		user := c.Get("user").(*jwt.Token)
-		claims := user.Claims.(jwt.MapClaims)
-		name := claims["name"].(string)
-		*out = "Welcome " + name + "!"
+	// Declare input port type.
+	type input struct {
+		User *jwtCustomClaims `auth:""`
+	}
+
+	return rest.NewHandler(func(c echo.Context, in input, out *string) error {
+		*out = "Welcome " + in.User.Name + "!"
 		return nil
 	})
 }
@@ -1,6 +1,6 @@
 module examples
 
-go 1.20
+go 1.22
 
 replace github.com/fourcels/rest => ../
 
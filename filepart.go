@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/jsonschema-go"
+)
+
+// FilePart streams a `multipart/form-data` upload part by part instead of buffering it.
+// Form fields sent before the first file part are bound and validated as usual,
+// the file itself is read by the Interactor as it arrives.
+type FilePart struct {
+	*multipart.Part
+	reader *multipart.Reader
+}
+
+// Next advances the stream to the following part, it returns io.EOF when the upload is complete.
+func (f *FilePart) Next() error {
+	if f.reader == nil {
+		return errNoFilePart
+	}
+	part, err := f.reader.NextPart()
+	if err != nil {
+		return err
+	}
+	f.Part = part
+	return nil
+}
+
+// JSONSchema documents the part as binary string.
+func (FilePart) JSONSchema() (jsonschema.Schema, error) {
+	s := jsonschema.Schema{}
+	s.AddType(jsonschema.String)
+	s.WithFormat("binary")
+	return s, nil
+}
+
+// MarshalJSON exposes the file name to validation, missing part is left out of validated
+// values, so the field is optional unless tagged `required:"true"`.
+func (f FilePart) MarshalJSON() ([]byte, error) {
+	if f.Part == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.FileName())
+}
+
+// optValue implements optional, only a received part is validated.
+func (f FilePart) optValue() (any, bool) {
+	return f, f.Part != nil
+}
+
+var (
+	filePartType  = reflect.TypeOf(FilePart{})
+	errNoFilePart = errors.New("no file part in request")
+)
+
+// requiredFilePart returns name of FilePart field of struct type typ tagged `required:"true"`.
+func requiredFilePart(typ reflect.Type) (string, bool) {
+	if typ.Kind() != reflect.Struct {
+		return "", false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type == filePartType && field.Tag.Get("required") == "true" {
+			return field.Tag.Get(string(ParamInFormData)), true
+		}
+	}
+	return "", false
+}
+
+// documentFilePart documents request body of operation with FilePart field as `multipart/form-data`,
+// the reflector knows only *multipart.FileHeader and multipart.File as file uploads.
+func (d *document) documentFilePart(op *operation) {
+	if _, ok := filePartField(reflect.ValueOf(op.interactor.Input()).Elem(), ""); !ok {
+		return
+	}
+	spec := op.spec()
+	if spec.RequestBody == nil || spec.RequestBody.RequestBody == nil {
+		return
+	}
+	content := spec.RequestBody.RequestBody.Content
+	if mt, ok := content[echo.MIMEApplicationForm]; ok {
+		delete(content, echo.MIMEApplicationForm)
+		content[echo.MIMEMultipartForm] = mt
+	}
+}
+
+// filePartField returns the FilePart field of struct value val tagged with name, the first
+// FilePart field is returned if name is empty.
+func filePartField(val reflect.Value, name string) (reflect.Value, bool) {
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		if typeField.Type != filePartType || !val.Field(i).CanSet() {
+			continue
+		}
+		if tag := typeField.Tag.Get(string(ParamInFormData)); tag != "" && (name == "" || tag == name) {
+			return val.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
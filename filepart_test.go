@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestFilePartLimits(t *testing.T) {
+	type input struct {
+		Title []string `formData:"title"`
+		Video FilePart `formData:"video"`
+	}
+	s := New(WithMiddleware())
+	s.POST("/upload", NewHandler(func(c echo.Context, in input, out *string) error {
+		content, err := io.ReadAll(in.Video)
+		*out = fmt.Sprintf("%d %d", len(strings.Join(in.Title, "")), len(content))
+		return err
+	}))
+
+	for _, tt := range []struct {
+		name   string
+		titles []string
+		want   int
+	}{
+		{"within limits", []string{strings.Repeat("a", defaultMemory-1), "b"}, http.StatusOK},
+		{"too large", []string{strings.Repeat("a", defaultMemory-1), "bc"}, http.StatusRequestEntityTooLarge},
+		{"too many", make([]string, maxFormParts+1), http.StatusRequestEntityTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			w := multipart.NewWriter(&body)
+			for _, title := range tt.titles {
+				if err := w.WriteField("title", title); err != nil {
+					t.Fatal(err)
+				}
+			}
+			part, err := w.CreateFormFile("video", "a.mp4")
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write([]byte("video"))
+			w.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+			req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d: %.200s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
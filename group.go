@@ -1,34 +1,70 @@
 package rest
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/labstack/echo/v4"
-	"github.com/mcuadros/go-defaults"
-	"github.com/swaggest/openapi-go/openapi3"
 )
 
 type NoContent struct{}
 
+// OutputWithHTTPStatus is implemented by outputs choosing status of their response, e.g. HealthReport,
+// others are responded with 200 OK. Statuses other than 200 are documented with options of operation.
+type OutputWithHTTPStatus interface {
+	HTTPStatus() int
+}
+
 type Group struct {
 	*echo.Group
-	prefix    string
-	ops       []option
-	reflector *openapi3.Reflector
+	prefix  string
+	ops     []option
+	service *Service
+	// unversioned operations are served outside of versions, see WithVersions.
+	unversioned bool
+	// middleware of Use, path versions are routed outside of echo.Group.
+	middleware []echo.MiddlewareFunc
+}
+
+// Use adds middleware to operations of group registered after it, in every version.
+func (g *Group) Use(middleware ...echo.MiddlewareFunc) {
+	g.Group.Use(middleware...)
+	g.middleware = append(g.middleware, middleware...)
 }
 
 func setHeader(c echo.Context, name, value string) {
 	c.Response().Header().Set(name, value)
 }
 func setCookie(c echo.Context, cookie, value string) {
-	options := strings.Split(cookie, ",")
-	options[0] = fmt.Sprintf("%s=%s", options[0], value)
-	c.Response().Header().Add("Set-Cookie", strings.Join(options, ";"))
+	name, attrs := parseCookieTag(cookie)
+	c.Response().Header().Add("Set-Cookie", strings.Join(append([]string{name + "=" + value}, attrs...), ";"))
+}
+
+// parseCookieTag splits `cookie` field tag into cookie name and attributes, e.g.
+// `sess,httponly,max-age=86400` into `sess` and `httponly`, `max-age=86400`.
+func parseCookieTag(tag string) (string, []string) {
+	options := strings.Split(tag, ",")
+	for i := range options {
+		options[i] = strings.TrimSpace(options[i])
+	}
+	return options[0], options[1:]
+}
+
+// formatValue formats header or cookie value, encoding.TextMarshaler values, e.g. time.Time,
+// in their text form to match documented schema.
+func formatValue(v reflect.Value) string {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprintf("%v", v)
 }
 
 func setupOutput(c echo.Context, out any) error {
@@ -49,15 +85,90 @@ func setupOutput(c echo.Context, out any) error {
 			continue
 		}
 		if header := typeField.Tag.Get("header"); header != "" {
-			setHeader(c, header, fmt.Sprintf("%v", structField))
+			setHeader(c, header, formatValue(structField))
 		}
 		if cookie := typeField.Tag.Get("cookie"); cookie != "" {
-			setCookie(c, cookie, fmt.Sprintf("%v", structField))
+			setCookie(c, cookie, formatValue(structField))
 		}
 	}
 	return nil
 }
 
+// outputBodies caches *outputBody of output types by type.
+var outputBodies sync.Map
+
+// outputBody is type of response body of an output type with fields sent as headers or cookies.
+type outputBody struct {
+	typ reflect.Type
+	// fields are indexes of output fields in order of typ.
+	fields []int
+}
+
+// bodyOf returns body type of output type typ without fields tagged `header` or `cookie` and
+// not `json`, they are out of body schema. Nil is returned if no field is left out or typ embeds
+// unexported types or types with methods, reflect can not build such types.
+func bodyOf(typ reflect.Type) (body *outputBody) {
+	if body, ok := outputBodies.Load(typ); ok {
+		return body.(*outputBody)
+	}
+	defer func() {
+		if recover() != nil {
+			body = nil
+		}
+		outputBodies.Store(typ, body)
+	}()
+	if typ.Kind() == reflect.Struct {
+		b := &outputBody{}
+		var fields []reflect.StructField
+		var dropped bool
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				if field.Anonymous {
+					return nil
+				}
+				continue
+			}
+			_, tagged := field.Tag.Lookup("json")
+			if !field.Anonymous && !tagged && (field.Tag.Get("header") != "" || field.Tag.Get("cookie") != "") {
+				dropped = true
+				continue
+			}
+			fields = append(fields, field)
+			b.fields = append(b.fields, i)
+		}
+		if dropped {
+			for i := range fields {
+				fields[i].Offset, fields[i].Index = 0, nil
+			}
+			b.typ = reflect.StructOf(fields)
+			body = b
+		}
+	}
+	return body
+}
+
+// encodeOutput writes out as JSON body with status, fields sent as headers or cookies are left out.
+// Outputs implementing json.Marshaler or encoding.TextMarshaler are encoded as they are.
+func encodeOutput(c echo.Context, status int, out any) error {
+	typ := reflect.TypeOf(out)
+	_, marshaler := out.(json.Marshaler)
+	_, textMarshaler := out.(encoding.TextMarshaler)
+	if marshaler || textMarshaler || typ == nil || typ.Kind() != reflect.Pointer {
+		return c.JSON(status, out)
+	}
+	body := bodyOf(typ.Elem())
+	if body == nil {
+		return c.JSON(status, out)
+	}
+	val := reflect.ValueOf(out).Elem()
+	res := reflect.New(body.typ).Elem()
+	for i, index := range body.fields {
+		res.Field(i).Set(val.Field(index))
+	}
+	return c.JSON(status, res.Interface())
+}
+
 func parenthesesToColon(pattern string) string {
 	re := regexp.MustCompile(`\{(\w+)\}`)
 	return re.ReplaceAllString(pattern, ":$1")
@@ -65,44 +176,40 @@ func parenthesesToColon(pattern string) string {
 
 // Method adds routes for `basePattern` that matches the `method` HTTP method.
 func (g *Group) add(method, pattern string, h Interactor, middleware ...echo.MiddlewareFunc) *echo.Route {
-	path := g.prefix + pattern
-	oc, err := g.reflector.NewOperationContext(method, path)
-	if err != nil {
-		log.Println(method, path, err)
+	ops := append(append([]option{}, g.service.ops...), g.ops...)
+	if len(g.service.versions) > 0 && !g.unversioned {
+		return g.service.addVersioned(g, method, pattern, h, ops, middleware)
 	}
+	op := g.service.addOperation(method, g.prefix+pattern, h, ops)
+	handler, middleware := g.service.handler(op, method, g.prefix+pattern, middleware)
+	return g.Add(method, parenthesesToColon(pattern), handler, middleware...)
+}
 
-	oc.SetSummary(h.Summary())
-
-	for _, op := range append(g.ops, h.Options()...) {
-		op(oc)
-	}
-
-	oc.AddReqStructure(h.Input())
-	oc.AddRespStructure(h.Output())
-
-	if err := g.reflector.AddOperation(oc); err != nil {
-		log.Println(method, path, err)
+// handler returns handler of operation served at route and its middleware.
+func (s *Service) handler(op *operation, method, route string, middleware []echo.MiddlewareFunc) (echo.HandlerFunc, []echo.MiddlewareFunc) {
+	op.binder = s.binder
+	op.validator = s.validator
+	if s.mock != nil {
+		// Examples refer to components of the spec of operation.
+		op.mock = &mocker{spec: op.document.OpenAPI}
 	}
-
-	return g.Add(method, parenthesesToColon(pattern), func(c echo.Context) error {
-		in := h.Input()
-		defaults.SetDefaults(in)
-		if err := c.Bind(in); err != nil {
-			return err
-		}
-		if err := c.Validate(in); err != nil {
-			return err
+	handler := op.handle
+	if s.scoped {
+		// Errors are rendered here to leave HTTPErrorHandler of the app untouched.
+		handler = func(c echo.Context) error {
+			if err := op.handle(c); err != nil {
+				customHTTPErrorHandler(err, c)
+			}
+			return nil
 		}
-		out := h.Output()
-		if err := h.Interact(c, in, out); err != nil {
-			return err
-		}
-		setupOutput(c, out)
-		if _, ok := out.(*NoContent); ok {
-			return c.NoContent(http.StatusNoContent)
-		}
-		return c.JSON(http.StatusOK, out)
-	}, middleware...)
+	}
+	middleware = append([]echo.MiddlewareFunc{s.instrument(method, route)}, middleware...)
+	middleware = append(middleware, s.verify(op))
+	if s.scoped && s.logger != nil {
+		// Access log of the app is left untouched, routes of Service are logged on their own.
+		middleware = append([]echo.MiddlewareFunc{s.logRequests}, middleware...)
+	}
+	return handler, middleware
 }
 
 func (g *Group) GET(pattern string, h Interactor, middleware ...echo.MiddlewareFunc) *echo.Route {
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestOutputHeaderFields(t *testing.T) {
+	type output struct {
+		Name  string  `json:"name"`
+		Token string  `header:"X-Token"`
+		Sess  string  `cookie:"sess"`
+		Both  string  `header:"X-Both" json:"both"`
+		Count int64   `json:"count"`
+		Score float64 `json:"score,omitempty"`
+	}
+	s := New(WithMiddleware())
+	s.GET("/out", NewHandler(func(c echo.Context, in struct{}, out *output) error {
+		*out = output{Token: "t", Sess: "s", Both: "b", Name: "n", Count: 9007199254740993, Score: 1e21}
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/out", nil))
+	if got, want := strings.TrimSpace(rec.Body.String()), `{"name":"n","both":"b","count":9007199254740993,"score":1e+21}`; got != want {
+		t.Errorf("body: got %s, want %s", got, want)
+	}
+	if got := rec.Header().Get("X-Token"); got != "t" {
+		t.Errorf("X-Token: got %q, want %q", got, "t")
+	}
+	if got := rec.Header().Get("X-Both"); got != "b" {
+		t.Errorf("X-Both: got %q, want %q", got, "b")
+	}
+	if got := rec.Header().Get(echo.HeaderSetCookie); !strings.HasPrefix(got, "sess=s") {
+		t.Errorf("Set-Cookie: got %q", got)
+	}
+
+	schema, err := s.OpenAPI.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"Token"`, `"Sess"`} {
+		if strings.Contains(string(schema), field) {
+			t.Errorf("schema has %s property", field)
+		}
+	}
+}
+
+type marshalerOutput struct {
+	Token string `header:"X-Token"`
+	Name  string
+}
+
+func (o marshalerOutput) MarshalJSON() ([]byte, error) {
+	return []byte(`{"custom":"` + o.Name + `","token":"` + o.Token + `"}`), nil
+}
+
+func TestOutputMarshaler(t *testing.T) {
+	s := New(WithMiddleware())
+	s.GET("/out", NewHandler(func(c echo.Context, in struct{}, out *marshalerOutput) error {
+		*out = marshalerOutput{Token: "t", Name: "n"}
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/out", nil))
+	if got, want := strings.TrimSpace(rec.Body.String()), `{"custom":"n","token":"t"}`; got != want {
+		t.Errorf("body: got %s, want %s", got, want)
+	}
+	if got := rec.Header().Get("X-Token"); got != "t" {
+		t.Errorf("X-Token: got %q, want %q", got, "t")
+	}
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"regexp"
 	"runtime"
 	"strings"
@@ -19,12 +20,13 @@ type Interactor interface {
 type Handler[i, o any] struct {
 	handler interact[i, o]
 	options []option
-	summary string
+	name    string
 }
 
 type interact[i, o any] func(c echo.Context, in i, out *o) error
 
-func getSummary() string {
+// getName returns name of the function calling NewHandler or NewUseCase, e.g. `login`.
+func getName() string {
 	counter, _, _, success := runtime.Caller(2)
 
 	if !success {
@@ -32,7 +34,7 @@ func getSummary() string {
 	}
 	name := strings.Split(runtime.FuncForPC(counter).Name(), ".")
 
-	return camelRegexp(name[len(name)-1])
+	return strings.TrimSuffix(name[len(name)-1], "-fm")
 }
 
 func camelRegexp(str string) string {
@@ -46,7 +48,7 @@ func NewHandler[i, o any](handler interact[i, o], ops ...option) Interactor {
 	return &Handler[i, o]{
 		handler: handler,
 		options: ops,
-		summary: getSummary(),
+		name:    getName(),
 	}
 }
 
@@ -65,5 +67,51 @@ func (h *Handler[i, o]) Options() []option {
 	return h.options
 }
 func (h *Handler[i, o]) Summary() string {
-	return h.summary
+	return camelRegexp(h.name)
+}
+
+// Name returns name of the function creating Handler, it is used to derive operationId.
+func (h *Handler[i, o]) Name() string {
+	return h.name
+}
+
+type useCase[i, o any] func(ctx context.Context, in i, out *o) error
+
+// UseCase is an Interactor independent of echo, request values are reachable from
+// context.Context with Principal, RequestID and ResponseHeader.
+type UseCase[i, o any] struct {
+	useCase useCase[i, o]
+	options []option
+	name    string
+}
+
+func NewUseCase[i, o any](useCase useCase[i, o], ops ...option) Interactor {
+	return &UseCase[i, o]{
+		useCase: useCase,
+		options: ops,
+		name:    getName(),
+	}
+}
+
+func (u *UseCase[i, o]) Interact(c echo.Context, in, out any) error {
+	return u.useCase(c.Request().Context(), *in.(*i), out.(*o))
+}
+
+func (u *UseCase[i, o]) Input() any {
+	return new(i)
+}
+
+func (u *UseCase[i, o]) Output() any {
+	return new(o)
+}
+func (u *UseCase[i, o]) Options() []option {
+	return u.options
+}
+func (u *UseCase[i, o]) Summary() string {
+	return camelRegexp(u.name)
+}
+
+// Name returns name of the function creating UseCase, it is used to derive operationId.
+func (u *UseCase[i, o]) Name() string {
+	return u.name
 }
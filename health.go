@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go"
+)
+
+// HealthChecker checks a dependency of Service, e.g. DB or cache.
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// HealthCheckFunc is a HealthChecker function.
+type HealthCheckFunc func(ctx context.Context) error
+
+// Check implements HealthChecker.
+func (f HealthCheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// PingCheck checks dependency with PingContext, e.g. *sql.DB.
+func PingCheck(pinger interface {
+	PingContext(ctx context.Context) error
+}) HealthChecker {
+	return HealthCheckFunc(pinger.PingContext)
+}
+
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFailing = "failing"
+)
+
+// HealthReport is aggregated result of health checks.
+type HealthReport struct {
+	Status string                       `json:"status" enum:"ok,failing"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HTTPStatus implements OutputWithHTTPStatus, failing report is responded with 503 Service Unavailable.
+func (r *HealthReport) HTTPStatus() int {
+	if r.Status != HealthStatusOK {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// HealthCheckResult is result of a single health check.
+type HealthCheckResult struct {
+	Status   string `json:"status" enum:"ok,failing"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration" example:"1.2ms"`
+}
+
+type healthCheck struct {
+	name    string
+	checker HealthChecker
+}
+
+// AddHealthCheck registers named checker run by readiness endpoint.
+func (s *Service) AddHealthCheck(name string, checker HealthChecker) {
+	s.healthChecks = append(s.healthChecks, healthCheck{name, checker})
+}
+
+// Health registers `livez` and `readyz` endpoints under pattern, they are documented with
+// Operations tag and are not logged. Readiness runs health checks in parallel, each limited
+// by WithHealthTimeout, and fails while Service is shutting down.
+func (s *Service) Health(pattern string) {
+	pattern = strings.TrimRight(pattern, "/")
+	ops := []option{
+		WithTags("Operations"),
+		func(oc openapi.OperationContext) {
+			oc.AddRespStructure(new(HealthReport), openapi.WithHTTPStatus(http.StatusServiceUnavailable))
+		},
+	}
+	g := s.Group("")
+	g.unversioned = true
+	g.GET(pattern+"/livez", NewUseCase(func(ctx context.Context, in struct{}, out *HealthReport) error {
+		out.Status = HealthStatusOK
+		return nil
+	}, append(ops, WithSummary("Liveness"), WithOperationID("livez"))...))
+	g.GET(pattern+"/readyz", NewUseCase(func(ctx context.Context, in struct{}, out *HealthReport) error {
+		*out = s.checkHealth(ctx)
+		return nil
+	}, append(ops, WithSummary("Readiness"), WithOperationID("readyz"))...))
+	s.unlogged[s.baseUrl+parenthesesToColon(pattern)+"/livez"] = true
+	s.unlogged[s.baseUrl+parenthesesToColon(pattern)+"/readyz"] = true
+}
+
+func (s *Service) checkHealth(ctx context.Context) HealthReport {
+	report := HealthReport{Status: HealthStatusOK, Checks: map[string]HealthCheckResult{}}
+	if !s.Ready() {
+		report.Status = HealthStatusFailing
+		report.Checks["shutdown"] = HealthCheckResult{Status: HealthStatusFailing, Error: "service is shutting down", Duration: "0s"}
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, hc := range s.healthChecks {
+		wg.Add(1)
+		go func(hc healthCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := runHealthCheck(ctx, hc.checker, s.healthTimeout)
+			res := HealthCheckResult{Status: HealthStatusOK, Duration: time.Since(start).String()}
+			if err != nil {
+				res.Status = HealthStatusFailing
+				res.Error = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[hc.name] = res
+			if err != nil {
+				report.Status = HealthStatusFailing
+			}
+		}(hc)
+	}
+	wg.Wait()
+	return report
+}
+
+// runHealthCheck returns when checker is done or timeout expires, whichever comes first.
+func runHealthCheck(ctx context.Context, checker HealthChecker, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// skipLogging reports whether request logging is disabled for route of c.
+func (s *Service) skipLogging(c echo.Context) bool {
+	return s.unlogged[c.Path()]
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	s := New(WithMiddleware())
+	failing := false
+	s.AddHealthCheck("db", HealthCheckFunc(func(ctx context.Context) error {
+		if failing {
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+	s.Health("/")
+
+	for _, tt := range []struct {
+		path    string
+		failing bool
+		code    int
+		status  string
+	}{
+		{"/livez", true, http.StatusOK, HealthStatusOK},
+		{"/readyz", false, http.StatusOK, HealthStatusOK},
+		{"/readyz", true, http.StatusServiceUnavailable, HealthStatusFailing},
+	} {
+		failing = tt.failing
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		var report HealthReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != tt.code || report.Status != tt.status {
+			t.Errorf("%s with failing check %v: got %d %q, want %d %q", tt.path, tt.failing,
+				rec.Code, report.Status, tt.code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build linux || darwin || freebsd
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+)
+
+// DiskSpaceCheck fails when file system of path has less than minFree bytes available.
+func DiskSpaceCheck(path string, minFree uint64) HealthChecker {
+	return HealthCheckFunc(func(ctx context.Context) error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return err
+		}
+		free := uint64(stat.Bavail) * uint64(stat.Bsize)
+		if free < minFree {
+			return fmt.Errorf("%d bytes free on %s, %d required", free, path, minFree)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Instrumentation returns middleware of operation served at method and documented route,
+// e.g. `GET` and `/users/{id}`, see packages metrics and tracing.
+type Instrumentation func(method, route string) echo.MiddlewareFunc
+
+// Instrument wraps every operation with middleware of i, operations registered before are
+// instrumented as well. Instrumentations run in order they were added, before route middleware.
+func (s *Service) Instrument(i Instrumentation) {
+	s.instruments = append(s.instruments, i)
+}
+
+// instrument runs instrumentations of Service on requests of route.
+func (s *Service) instrument(method, route string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			h := next
+			for i := len(s.instruments) - 1; i >= 0; i-- {
+				h = s.instruments[i](method, route)(h)
+			}
+			return h(c)
+		}
+	}
+}
+
+// Handle serves h for method at pattern under base URL outside of operations, e.g. metrics
+// endpoint. It is neither documented nor logged.
+func (s *Service) Handle(method, pattern string, h http.Handler) {
+	pattern = s.baseUrl + strings.TrimRight(pattern, "/")
+	s.router.Add(method, pattern, echo.WrapHandler(h))
+	s.unlogged[pattern] = true
+}
+
+// FailedPhase returns phase of request pipeline the request failed in: `bind`, `validate`,
+// `interact` or `encode`.
+func FailedPhase(c echo.Context) (string, bool) {
+	phase, ok := c.Get(phaseKey).(string)
+	return phase, ok
+}
+
+// PhaseObserver is called when a phase of request pipeline starts, the returned function is
+// called with error of the phase when it ends.
+type PhaseObserver func(c echo.Context, phase string) func(err error)
+
+// ObservePhases makes o observe phases of request served with c, e.g. to trace them.
+func ObservePhases(c echo.Context, o PhaseObserver) {
+	c.Set(phaseObserverKey, o)
+}
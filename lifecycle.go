@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Hook is run by Service.Run on start or shutdown, e.g. to open and close DB pools.
+type Hook func(ctx context.Context) error
+
+// shutdownHook is OnShutdown hook with number of OnStart hooks registered before it.
+type shutdownHook struct {
+	hook    Hook
+	started int
+}
+
+// OnStart registers hook run before server starts listening, hooks run in order of registration.
+// If a hook fails, OnShutdown hooks registered before it are run and the server is not started.
+func (s *Service) OnStart(hook Hook) {
+	s.onStart = append(s.onStart, hook)
+}
+
+// OnShutdown registers hook run after in-flight requests are drained, hooks run in reverse order
+// of registration, e.g. to close resources opened by OnStart hooks registered before.
+func (s *Service) OnShutdown(hook Hook) {
+	s.onShutdown = append(s.onShutdown, shutdownHook{hook: hook, started: len(s.onStart)})
+}
+
+// Ready reports whether Service accepts traffic, it turns false once shutdown begins.
+func (s *Service) Ready() bool {
+	return !s.draining.Load()
+}
+
+// Run starts OnStart hooks and the server on address, then blocks until ctx is done or
+// SIGINT/SIGTERM is received. On shutdown readiness flips to failing, new connections are refused
+// after WithDrainDelay, in-flight requests are drained and OnShutdown hooks are run within
+// WithShutdownTimeout.
+func (s *Service) Run(ctx context.Context, address string) error {
+	if s.Echo == nil {
+		return errors.New("rest: Run requires Service with echo instance")
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for i, hook := range s.onStart {
+		if err := hook(ctx); err != nil {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+			defer cancel()
+			return errors.Join(append([]error{err}, s.shutdown(shutdownCtx, i)...)...)
+		}
+	}
+
+	s.draining.Store(false)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.Echo.Start(address)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	if err == nil && s.drainDelay > 0 {
+		time.Sleep(s.drainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	errs := []error{err}
+	if err := s.Echo.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, s.shutdown(shutdownCtx, len(s.onStart))...)
+	return errors.Join(errs...)
+}
+
+// shutdown runs OnShutdown hooks registered after no more than started OnStart hooks, in reverse
+// order of registration.
+func (s *Service) shutdown(ctx context.Context, started int) []error {
+	var errs []error
+	for i := len(s.onShutdown) - 1; i >= 0; i-- {
+		if h := s.onShutdown[i]; h.started <= started {
+			if err := h.hook(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// events records run hooks.
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) hook(name string, err error) Hook {
+	return func(ctx context.Context) error {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.list = append(e.list, name)
+		return err
+	}
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+func TestRun(t *testing.T) {
+	s := New(WithMiddleware(), WithHidePort(true))
+	var e events
+	s.OnStart(e.hook("open db", nil))
+	s.OnShutdown(e.hook("close db", nil))
+	s.OnStart(e.hook("open cache", nil))
+	s.OnShutdown(func(ctx context.Context) error {
+		if s.Ready() {
+			t.Error("service is ready on shutdown")
+		}
+		return e.hook("close cache", nil)(ctx)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for s.Echo.ListenerAddr() == nil {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	if err := s.Run(ctx, "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"open db", "open cache", "close cache", "close db"}; !reflect.DeepEqual(e.get(), want) {
+		t.Errorf("got %q, want %q", e.get(), want)
+	}
+}
+
+func TestRunStartFails(t *testing.T) {
+	s := New(WithMiddleware())
+	var e events
+	errCache := errors.New("cache is unavailable")
+	s.OnStart(e.hook("open db", nil))
+	s.OnShutdown(e.hook("close db", nil))
+	s.OnStart(e.hook("open cache", errCache))
+	s.OnShutdown(e.hook("close cache", nil))
+	s.OnStart(e.hook("open queue", nil))
+	s.OnShutdown(e.hook("close queue", nil))
+
+	if err := s.Run(context.Background(), "127.0.0.1:0"); !errors.Is(err, errCache) {
+		t.Fatalf("got %v, want %v", err, errCache)
+	}
+	if want := []string{"open db", "open cache", "close db"}; !reflect.DeepEqual(e.get(), want) {
+		t.Errorf("got %q, want %q", e.get(), want)
+	}
+	if s.Echo.ListenerAddr() != nil {
+		t.Error("server is started")
+	}
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+	s := New(WithMiddleware(), WithHidePort(true), WithShutdownTimeout(50*time.Millisecond))
+	s.OnShutdown(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := s.Run(ctx, "127.0.0.1:0"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %s", elapsed)
+	}
+}
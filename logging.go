@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	operationKey = "rest.operation"
+	inputKey     = "rest.input"
+	outputKey    = "rest.output"
+	redacted     = "[REDACTED]"
+)
+
+// logRequests writes structured access log of requests with slog, bodies of operations are
+// logged with WithBodyLogging, fields tagged `sensitive` are redacted and `log:"-"`, `auth` and
+// `ctx` are omitted.
+func (s *Service) logRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.skipLogging(c) {
+			return next(c)
+		}
+		start := time.Now()
+		err := next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		req, res := c.Request(), c.Response()
+		requestID := res.Header().Get(echo.HeaderXRequestID)
+		if requestID == "" {
+			requestID = req.Header.Get(echo.HeaderXRequestID)
+		}
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("uri", req.RequestURI),
+			slog.Int("status", res.Status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int64("bytes_out", res.Size),
+		}
+		if requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+		if op, ok := c.Get(operationKey).(*operation); ok {
+			attrs = append(attrs, slog.String("operation", op.id()), slog.String("route", op.path))
+			if s.logPrincipal != nil {
+				if principal, err := op.binder.principal(c); err == nil && principal != nil {
+					attrs = append(attrs, slog.String("principal", s.logPrincipal(principal)))
+				}
+			}
+		}
+		if s.logBodies {
+			if in := c.Get(inputKey); in != nil {
+				attrs = append(attrs, slog.Any("input", redact(in)))
+			}
+			if out := c.Get(outputKey); out != nil {
+				attrs = append(attrs, slog.Any("output", redact(out)))
+			}
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case res.Status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case res.Status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		s.logger.LogAttrs(req.Context(), level, "request", attrs...)
+		return err
+	}
+}
+
+// redact returns v with `sensitive` fields replaced and `log:"-"`, `auth` and `ctx` fields omitted.
+func redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redactValue(reflect.ValueOf(v))
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func redactValue(val reflect.Value) any {
+	if val.CanInterface() {
+		if opt, ok := val.Interface().(optional); ok {
+			v, _ := opt.optValue()
+			return redact(v)
+		}
+	}
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		return redactValue(val.Elem())
+	case reflect.Struct:
+		if val.Type().Implements(jsonMarshalerType) || reflect.PointerTo(val.Type()).Implements(jsonMarshalerType) {
+			return val.Interface()
+		}
+		fields := map[string]any{}
+		redactStruct(val, fields)
+		return fields
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil
+		}
+		items := make([]any, val.Len())
+		for i := range items {
+			items[i] = redactValue(val.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		items := make(map[string]any, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			items[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value())
+		}
+		return items
+	}
+	if !val.CanInterface() {
+		return nil
+	}
+	return val.Interface()
+}
+
+func redactStruct(val reflect.Value, fields map[string]any) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Tag.Get("log") == "-" {
+			continue
+		}
+		// Principal and context values are not input of request, see WithPrincipalLogging.
+		if _, ok := field.Tag.Lookup("auth"); ok {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("ctx"); ok {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			redactStruct(val.Field(i), fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := logName(field)
+		if name == "" {
+			continue
+		}
+		if sensitive, ok := field.Tag.Lookup("sensitive"); ok && sensitive != "false" {
+			fields[name] = redacted
+			continue
+		}
+		fields[name] = redactValue(val.Field(i))
+	}
+}
+
+// logName returns name of field in input location or JSON, empty for omitted fields.
+func logName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "query", "path", "header", "cookie"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBodyLoggingOmitsPrincipal(t *testing.T) {
+	type claims struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+		Token   string `json:"token"`
+	}
+	type input struct {
+		User    *claims `auth:""`
+		Tenant  string  `ctx:"tenant"`
+		Name    string  `json:"name"`
+		Comment string  `json:"comment" sensitive:""`
+	}
+
+	for name, identify := range map[string]func(principal any) string{
+		"without principal logging": nil,
+		"with principal logging":    func(principal any) string { return principal.(*claims).Subject },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ops := []serviceOption{WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))), WithBodyLogging()}
+			if identify != nil {
+				ops = append(ops, WithPrincipalLogging(identify))
+			}
+			s := New(ops...)
+			s.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set("tenant", "acme-secret")
+					return next(c)
+				}
+			})
+			s.WithPrincipal(func(c echo.Context) (any, error) {
+				return &claims{Subject: "u1", Email: "a@b.c", Token: "secret"}, nil
+			})
+			s.POST("/hello", NewHandler(func(c echo.Context, in input, out *string) error {
+				*out = "hello " + in.Name
+				return nil
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/hello", strings.NewReader(`{"name":"n","comment":"c"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+			}
+
+			log := buf.String()
+			if !strings.Contains(log, `"input":{"comment":"[REDACTED]","name":"n"}`) {
+				t.Errorf("input is not logged: %s", log)
+			}
+			for _, leaked := range []string{"a@b.c", "secret", `"User"`, `"Tenant"`} {
+				if strings.Contains(log, leaked) {
+					t.Errorf("log has %s: %s", leaked, log)
+				}
+			}
+			if logged := strings.Contains(log, `"principal":"u1"`); logged != (identify != nil) {
+				t.Errorf("principal logged %v: %s", logged, log)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package metrics collects Prometheus metrics of rest.Service operations.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fourcels/rest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics collects Prometheus metrics of operations, labelled by method and documented route pattern.
+type metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	failures     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+func newMetrics(registry *prometheus.Registry) *metrics {
+	sizeBuckets := prometheus.ExponentialBuckets(100, 10, 7)
+	m := &metrics{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rest_requests_total",
+			Help: "Number of handled requests.",
+		}, []string{"method", "route", "status"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rest_request_failures_total",
+			Help: "Number of failed requests by pipeline phase: bind, validate, interact or encode.",
+		}, []string{"method", "route", "phase"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rest_request_duration_seconds",
+			Help:    "Request latency.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rest_requests_in_flight",
+			Help: "Number of requests being served.",
+		}, []string{"method", "route"}),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rest_request_size_bytes",
+			Help:    "Request body size.",
+			Buckets: sizeBuckets,
+		}, []string{"method", "route"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rest_response_size_bytes",
+			Help:    "Response body size.",
+			Buckets: sizeBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+	registry.MustRegister(m.requests, m.failures, m.duration, m.inFlight, m.requestSize, m.responseSize)
+	return m
+}
+
+// statusClass returns e.g. 2xx for 201.
+func statusClass(code int) string {
+	return fmt.Sprintf("%dxx", code/100)
+}
+
+// serve observes request of route, errors are rendered first to know response status and size.
+func (m *metrics) serve(c echo.Context, next echo.HandlerFunc, method, route string) error {
+	inFlight := m.inFlight.WithLabelValues(method, route)
+	inFlight.Inc()
+	defer inFlight.Dec()
+	start := time.Now()
+
+	err := next(c)
+	if err != nil {
+		c.Error(err)
+	}
+	// Mounted Service renders errors of operation itself, the failed phase is recorded anyway.
+	if phase, ok := rest.FailedPhase(c); ok {
+		m.failures.WithLabelValues(method, route, phase).Inc()
+	} else if err != nil {
+		m.failures.WithLabelValues(method, route, "middleware").Inc()
+	}
+
+	status := statusClass(c.Response().Status)
+	m.requests.WithLabelValues(method, route, status).Inc()
+	m.duration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	if size := c.Request().ContentLength; size >= 0 {
+		m.requestSize.WithLabelValues(method, route).Observe(float64(size))
+	}
+	m.responseSize.WithLabelValues(method, route, status).Observe(float64(c.Response().Size))
+	return err
+}
+
+// Serve enables Prometheus metrics of operations of s and serves them in text format at pattern.
+// Operations are labelled by method, documented route, e.g. `/users/{id}`, and status class.
+// Metrics are collected into registry, a new one is created if omitted.
+func Serve(s *rest.Service, pattern string, registry ...*prometheus.Registry) {
+	reg := prometheus.NewRegistry()
+	if len(registry) > 0 {
+		reg = registry[0]
+	}
+	m := newMetrics(reg)
+	s.Instrument(func(method, route string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				return m.serve(c, next, method, route)
+			}
+		}
+	})
+	s.Handle(http.MethodGet, pattern, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fourcels/rest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestServe(t *testing.T) {
+	s := rest.New(rest.WithBaseURL("/api"))
+	s.GET("/users/{id}", rest.NewHandler(func(c echo.Context, in struct {
+		ID int `path:"id" minimum:"1"`
+	}, out *string) error {
+		if in.ID == 2 {
+			return rest.HTTPCodeAsError(http.StatusNotFound)
+		}
+		*out = "user"
+		return nil
+	}))
+	reg := prometheus.NewRegistry()
+	Serve(s, "/metrics", reg)
+
+	for _, path := range []string{"/api/users/1", "/api/users/1", "/api/users/2", "/api/users/0", "/api/users/x"} {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	requests := `
+# HELP rest_requests_total Number of handled requests.
+# TYPE rest_requests_total counter
+rest_requests_total{method="GET",route="/users/{id}",status="2xx"} 2
+rest_requests_total{method="GET",route="/users/{id}",status="4xx"} 3
+`
+	if err := testutil.CollectAndCompare(reg, strings.NewReader(requests), "rest_requests_total"); err != nil {
+		t.Error(err)
+	}
+	failures := `
+# HELP rest_request_failures_total Number of failed requests by pipeline phase: bind, validate, interact or encode.
+# TYPE rest_request_failures_total counter
+rest_request_failures_total{method="GET",phase="bind",route="/users/{id}"} 1
+rest_request_failures_total{method="GET",phase="interact",route="/users/{id}"} 1
+rest_request_failures_total{method="GET",phase="validate",route="/users/{id}"} 1
+`
+	if err := testutil.CollectAndCompare(reg, strings.NewReader(failures), "rest_request_failures_total"); err != nil {
+		t.Error(err)
+	}
+	inFlight := `
+# HELP rest_requests_in_flight Number of requests being served.
+# TYPE rest_requests_in_flight gauge
+rest_requests_in_flight{method="GET",route="/users/{id}"} 0
+`
+	if err := testutil.CollectAndCompare(reg, strings.NewReader(inFlight), "rest_requests_in_flight"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(reg, "rest_request_duration_seconds"); n != 2 {
+		t.Errorf("duration has %d series, want 2", n)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `rest_requests_total{method="GET",route="/users/{id}",status="2xx"} 2`) {
+		t.Errorf("metrics endpoint: %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,410 @@
+package rest
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// HeaderPrefer selects status and named example of mock response, e.g. `Prefer: code=404, example=missing`.
+const HeaderPrefer = "Prefer"
+
+// namedExample is named example of response with status, or of request body if status is 0.
+type namedExample struct {
+	status int
+	name   string
+	value  any
+}
+
+// WithResponseExample documents named example of response with status, it is served in mock mode.
+func WithResponseExample(status int, name string, value any) option {
+	return operationOption(func(op *operation) {
+		op.examples = append(op.examples, namedExample{status, name, value})
+	})
+}
+
+// WithRequestExample documents named example of request body.
+func WithRequestExample(name string, value any) option {
+	return operationOption(func(op *operation) {
+		op.examples = append(op.examples, namedExample{0, name, value})
+	})
+}
+
+// documentExamples adds examples of operation to its documented request body and responses.
+func (d *document) documentExamples(op *operation) {
+	spec := op.spec()
+	for _, ex := range op.examples {
+		var content map[string]openapi3.MediaType
+		if ex.status == 0 {
+			if spec.RequestBody == nil || spec.RequestBody.RequestBody == nil {
+				d.logger.Error("add request example", "method", op.method, "path", op.path,
+					"error", "request body is not documented")
+				continue
+			}
+			content = spec.RequestBody.RequestBody.Content
+		} else {
+			res, ok := spec.Responses.MapOfResponseOrRefValues[strconv.Itoa(ex.status)]
+			if !ok || res.Response == nil {
+				d.logger.Error("add response example", "method", op.method, "path", op.path,
+					"status", ex.status, "error", "status is not documented")
+				continue
+			}
+			content = res.Response.Content
+		}
+		value := ex.value
+		for mediaType, c := range content {
+			if c.Examples == nil {
+				c.Examples = map[string]openapi3.ExampleOrRef{}
+			}
+			c.Examples[ex.name] = openapi3.ExampleOrRef{Example: &openapi3.Example{Value: &value}}
+			content[mediaType] = c
+		}
+	}
+}
+
+// mocker answers operations of spec with examples, documented or synthesized from schema.
+type mocker struct {
+	spec *openapi3.Spec
+}
+
+// MockHandler serves examples of every operation of spec, e.g. loaded from openapi.json.
+// Requests are routed by path but are not bound or validated, see WithMock.
+func MockHandler(spec *openapi3.Spec) http.Handler {
+	m := &mocker{spec: spec}
+	mux := http.NewServeMux()
+	base := ""
+	if len(spec.Servers) > 0 {
+		if u, err := url.Parse(spec.Servers[0].URL); err == nil {
+			base = strings.TrimRight(u.Path, "/")
+		}
+	}
+	for path, item := range spec.Paths.MapOfPathItemValues {
+		for method, op := range item.MapOfOperationValues {
+			op := op
+			mux.HandleFunc(strings.ToUpper(method)+" "+base+path, func(w http.ResponseWriter, r *http.Request) {
+				m.respond(w, r, &op)
+			})
+		}
+	}
+	return mux
+}
+
+// respond writes example of response selected with Prefer header, the first documented
+// success response by default.
+func (m *mocker) respond(w http.ResponseWriter, r *http.Request, op *openapi3.Operation) {
+	prefer := parsePrefer(r.Header.Get(HeaderPrefer))
+	codes := make([]string, 0, len(op.Responses.MapOfResponseOrRefValues))
+	for code := range op.Responses.MapOfResponseOrRefValues {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	status := prefer["code"]
+	if status == "" {
+		for _, code := range codes {
+			if strings.HasPrefix(code, "2") {
+				status = code
+				break
+			}
+		}
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		code = http.StatusOK
+	}
+	res, ok := op.Responses.MapOfResponseOrRefValues[status]
+	if !ok || res.Response == nil {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set(HeaderPrefer, "code="+status)
+	content, ok := res.Response.Content[echo.MIMEApplicationJSON]
+	if !ok {
+		w.WriteHeader(code)
+		return
+	}
+	value := m.example(content, prefer["example"])
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// example returns named example of content, documented one or synthesized from schema.
+func (m *mocker) example(content openapi3.MediaType, name string) any {
+	if name != "" {
+		if ex, ok := content.Examples[name]; ok && ex.Example != nil && ex.Example.Value != nil {
+			return *ex.Example.Value
+		}
+	}
+	if content.Example != nil {
+		return *content.Example
+	}
+	names := make([]string, 0, len(content.Examples))
+	for n := range content.Examples {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if ex := content.Examples[n]; ex.Example != nil && ex.Example.Value != nil {
+			return *ex.Example.Value
+		}
+	}
+	return m.synthesize(content.Schema, map[string]bool{})
+}
+
+// synthesize returns value of schema from its example, default or enum, or a placeholder of its type
+// within its limits.
+// Schemas referenced by themselves are synthesized once, seen holds references being synthesized.
+func (m *mocker) synthesize(s *openapi3.SchemaOrRef, seen map[string]bool) any {
+	if s == nil {
+		return nil
+	}
+	if s.SchemaReference != nil {
+		name := strings.TrimPrefix(s.SchemaReference.Ref, "#/components/schemas/")
+		if seen[name] || m.spec.Components == nil || m.spec.Components.Schemas == nil {
+			return nil
+		}
+		ref, ok := m.spec.Components.Schemas.MapOfSchemaOrRefValues[name]
+		if !ok {
+			return nil
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return m.synthesize(&ref, seen)
+	}
+	schema := s.Schema
+	if schema == nil {
+		return nil
+	}
+	switch {
+	case schema.Example != nil:
+		return *schema.Example
+	case schema.Default != nil:
+		return *schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		res := map[string]any{}
+		for i := range schema.AllOf {
+			if v, ok := m.synthesize(&schema.AllOf[i], seen).(map[string]any); ok {
+				for k, item := range v {
+					res[k] = item
+				}
+			}
+		}
+		return res
+	case len(schema.OneOf) > 0:
+		return m.synthesize(&schema.OneOf[0], seen)
+	case len(schema.AnyOf) > 0:
+		return m.synthesize(&schema.AnyOf[0], seen)
+	}
+	typ := openapi3.SchemaTypeObject
+	if schema.Type != nil {
+		typ = *schema.Type
+	} else if len(schema.Properties) == 0 {
+		return nil
+	}
+	switch typ {
+	case openapi3.SchemaTypeString:
+		return mockString(schema)
+	case openapi3.SchemaTypeInteger:
+		return int64(mockNumber(schema, 1))
+	case openapi3.SchemaTypeNumber:
+		return mockNumber(schema, 0)
+	case openapi3.SchemaTypeBoolean:
+		return false
+	case openapi3.SchemaTypeArray:
+		item := m.synthesize(schema.Items, seen)
+		if item == nil {
+			return []any{}
+		}
+		res := []any{item}
+		if schema.MinItems != nil {
+			for len(res) < int(*schema.MinItems) {
+				res = append(res, item)
+			}
+		}
+		return res
+	case openapi3.SchemaTypeObject:
+		res := map[string]any{}
+		for name := range schema.Properties {
+			prop := schema.Properties[name]
+			if v := m.synthesize(&prop, seen); v != nil {
+				res[name] = v
+			}
+		}
+		if ap := schema.AdditionalProperties; ap != nil && ap.SchemaOrRef != nil && len(res) == 0 {
+			if v := m.synthesize(ap.SchemaOrRef, seen); v != nil {
+				res["key"] = v
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// formatExamples are values of string formats asserted by response validation.
+var formatExamples = map[string]string{
+	"date-time":     "2024-01-01T00:00:00Z",
+	"date":          "2024-01-01",
+	"time":          "00:00:00Z",
+	"duration":      "P1D",
+	"email":         "user@example.com",
+	"hostname":      "example.com",
+	"ipv4":          "192.0.2.1",
+	"ipv6":          "2001:db8::1",
+	"uuid":          "00000000-0000-0000-0000-000000000000",
+	"uri":           "https://example.com",
+	"url":           "https://example.com",
+	"uri-reference": "/",
+	"byte":          "c3RyaW5n",
+}
+
+// mockString returns string of format, or matching pattern, within length limits of schema.
+func mockString(schema *openapi3.Schema) string {
+	if schema.Format != nil {
+		if v, ok := formatExamples[*schema.Format]; ok {
+			return v
+		}
+	}
+	minLength, maxLength := 0, -1
+	if schema.MinLength != nil {
+		minLength = int(*schema.MinLength)
+	}
+	if schema.MaxLength != nil {
+		maxLength = int(*schema.MaxLength)
+	}
+	if schema.Pattern != nil {
+		if v, ok := patternString(*schema.Pattern, minLength, maxLength); ok {
+			return v
+		}
+	}
+	res := "string"
+	if len(res) < minLength {
+		res += strings.Repeat("s", minLength-len(res))
+	}
+	if maxLength >= 0 && len(res) > maxLength {
+		res = res[:maxLength]
+	}
+	return res
+}
+
+// patternString returns string matching pattern within length limits, unbounded repetitions of
+// pattern are repeated more until the string is long enough.
+func patternString(pattern string, minLength, maxLength int) (string, bool) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	parsed = parsed.Simplify()
+	for extra := 0; extra <= minLength; extra++ {
+		var b strings.Builder
+		writePattern(&b, parsed, extra)
+		res := b.String()
+		if n := utf8.RuneCountInString(res); n >= minLength && (maxLength < 0 || n <= maxLength) &&
+			re.MatchString(res) {
+			return res, true
+		}
+	}
+	return "", false
+}
+
+// writePattern writes the shortest string matching re, unbounded repetitions are repeated extra times.
+func writePattern(b *strings.Builder, re *syntax.Regexp, extra int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			r := re.Rune[0]
+			// Prefer a letter of the class to its first rune, e.g. of `[^,]`.
+			for i := 0; i+1 < len(re.Rune); i += 2 {
+				if re.Rune[i] <= 'a' && 'a' <= re.Rune[i+1] {
+					r = 'a'
+					break
+				}
+			}
+			b.WriteRune(r)
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte('a')
+	case syntax.OpCapture:
+		writePattern(b, re.Sub[0], extra)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePattern(b, sub, extra)
+		}
+	case syntax.OpAlternate:
+		writePattern(b, re.Sub[0], extra)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		n := 0
+		switch re.Op {
+		case syntax.OpPlus:
+			n = 1 + extra
+		case syntax.OpStar:
+			n = extra
+		case syntax.OpRepeat:
+			n = re.Min + extra
+			if re.Max >= 0 && n > re.Max {
+				n = re.Max
+			}
+		}
+		for i := 0; i < n; i++ {
+			writePattern(b, re.Sub[0], extra)
+		}
+	}
+}
+
+// mockNumber returns number within limits of schema, step is 1 for integers.
+func mockNumber(schema *openapi3.Schema, step float64) float64 {
+	res := 0.0
+	if schema.Minimum != nil {
+		res = *schema.Minimum
+		if step > 0 {
+			res = math.Ceil(res)
+		}
+		if schema.ExclusiveMinimum != nil && *schema.ExclusiveMinimum && res == *schema.Minimum {
+			res += math.Max(step, 1)
+		}
+	} else if schema.Maximum != nil && res > *schema.Maximum {
+		res = *schema.Maximum
+		if step > 0 {
+			res = math.Floor(res)
+		}
+		if schema.ExclusiveMaximum != nil && *schema.ExclusiveMaximum && res == *schema.Maximum {
+			res -= math.Max(step, 1)
+		}
+	}
+	if m := schema.MultipleOf; m != nil && *m > 0 {
+		res = math.Ceil(res / *m) * *m
+	}
+	return res
+}
+
+// parsePrefer parses preferences of Prefer header, e.g. `code=404, example=missing`.
+func parsePrefer(header string) map[string]string {
+	res := map[string]string{}
+	for _, pref := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+		res[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return res
+}
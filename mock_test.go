@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMockValidResponses(t *testing.T) {
+	type user struct {
+		ID      int64     `json:"id" minimum:"1"`
+		Code    string    `json:"code" pattern:"^[A-Z]{3}-\\d{4}$"`
+		Slug    string    `json:"slug" pattern:"^[a-z]+(-[a-z]+)*$" minLength:"8"`
+		Name    string    `json:"name" minLength:"10" maxLength:"12"`
+		Short   string    `json:"short" maxLength:"3"`
+		Email   string    `json:"email" format:"email"`
+		Host    string    `json:"host" format:"hostname"`
+		Created time.Time `json:"created"`
+		Score   float64   `json:"score" minimum:"0.3" multipleOf:"0.5"`
+		Debt    int       `json:"debt" maximum:"-10"`
+		Tags    []string  `json:"tags" minItems:"2" uniqueItems:"false"`
+	}
+	s := New(WithMock(), WithMiddleware())
+	s.GET("/users", NewHandler(func(c echo.Context, in struct{}, out *[]user) error {
+		t.Error("Interactor is called")
+		return nil
+	}, WithOperationID("listUsers")))
+	s.ValidateResponses(func(c echo.Context, err *ContractError) error { return err })
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestPatternString(t *testing.T) {
+	for _, tt := range []struct {
+		pattern              string
+		minLength, maxLength int
+		ok                   bool
+	}{
+		{`^\d{3}$`, 0, -1, true},
+		{`^[a-z]+@[a-z]+\.com$`, 12, -1, true},
+		{`^(ab|cd)*x?$`, 5, 6, true},
+		{`^[^,]{2,4}$`, 0, 3, true},
+		{`^a{5}$`, 0, 4, false},
+	} {
+		res, ok := patternString(tt.pattern, tt.minLength, tt.maxLength)
+		if ok != tt.ok {
+			t.Errorf("%s: got %q, want ok %v", tt.pattern, res, tt.ok)
+			continue
+		}
+		if ok && (!regexp.MustCompile(tt.pattern).MatchString(res) || len(res) < tt.minLength ||
+			tt.maxLength >= 0 && len(res) > tt.maxLength) {
+			t.Errorf("%s: got %q out of limits %d..%d", tt.pattern, res, tt.minLength, tt.maxLength)
+		}
+	}
+}
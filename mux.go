@@ -0,0 +1,128 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Mux mounts Interactors on a standard http.ServeMux with the same binding, validation,
+// error handling and OpenAPI documentation as Service. Routes are registered with
+// Go 1.22 method and pattern syntax, e.g. `GET /users/{id}`. Wildcards spanning several
+// segments, e.g. `{path...}`, can not be described by OpenAPI and are rejected.
+type Mux struct {
+	*http.ServeMux
+	*document
+	baseUrl   string
+	ops       []option
+	binder    *CustomBinder
+	validator *CustomValidator
+	// echo creates contexts for binder, validator and Interactors, it does not route requests.
+	echo *echo.Echo
+}
+
+func NewMux(baseUrl ...string) *Mux {
+	m := &Mux{}
+
+	if len(baseUrl) > 0 {
+		m.baseUrl = baseUrl[0]
+	}
+	m.document = newDocument(m.baseUrl)
+	m.ServeMux = http.NewServeMux()
+
+	m.binder = &CustomBinder{}
+	m.validator = &CustomValidator{}
+	m.echo = echo.New()
+
+	return m
+}
+
+var muxParamRegexp = regexp.MustCompile(`\{(\w+)(\.\.\.)?\}`)
+
+// add registers Interactor h for method and pattern, it panics on wildcards of several segments.
+func (m *Mux) add(method, pattern string, h Interactor, middleware ...func(http.Handler) http.Handler) {
+	var names []string
+	for _, match := range muxParamRegexp.FindAllStringSubmatch(pattern, -1) {
+		if match[2] != "" {
+			panic(fmt.Sprintf("rest: %s %s: wildcard %s is not supported, use {%s}", method, pattern, match[0], match[1]))
+		}
+		names = append(names, match[1])
+	}
+	op := m.addOperation(method, pattern, h, m.ops)
+	op.binder = m.binder
+	op.validator = m.validator
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := m.echo.NewContext(r, w)
+		values := make([]string, len(names))
+		for i, name := range names {
+			values[i] = r.PathValue(name)
+		}
+		c.SetPath(m.baseUrl + pattern)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		if err := m.serve(c, op); err != nil {
+			customHTTPErrorHandler(err, c)
+		}
+	})
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	m.ServeMux.Handle(method+" "+m.baseUrl+pattern, handler)
+}
+
+// serve handles request with operation, panics are reported as errors.
+func (m *Mux) serve(c echo.Context, op *operation) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	return op.handle(c)
+}
+
+// BaseURL returns path prefix of all routes.
+func (m *Mux) BaseURL() string {
+	return m.baseUrl
+}
+
+func (m *Mux) GET(pattern string, h Interactor, middleware ...func(http.Handler) http.Handler) {
+	m.add(http.MethodGet, pattern, h, middleware...)
+}
+func (m *Mux) POST(pattern string, h Interactor, middleware ...func(http.Handler) http.Handler) {
+	m.add(http.MethodPost, pattern, h, middleware...)
+}
+func (m *Mux) PATCH(pattern string, h Interactor, middleware ...func(http.Handler) http.Handler) {
+	m.add(http.MethodPatch, pattern, h, middleware...)
+}
+func (m *Mux) PUT(pattern string, h Interactor, middleware ...func(http.Handler) http.Handler) {
+	m.add(http.MethodPut, pattern, h, middleware...)
+}
+func (m *Mux) DELETE(pattern string, h Interactor, middleware ...func(http.Handler) http.Handler) {
+	m.add(http.MethodDelete, pattern, h, middleware...)
+}
+
+func (m *Mux) Docs(pattern string, config ...map[string]any) {
+	pattern = strings.TrimRight(pattern, "/")
+	m.ServeMux.HandleFunc(http.MethodGet+" "+m.baseUrl+pattern+"/openapi.json", m.specHandler)
+	m.ServeMux.Handle(http.MethodGet+" "+m.baseUrl+pattern+"/", m.uiHandler(m.baseUrl+pattern+"/openapi.json", config...))
+}
+
+// WithPrincipal sets extractor of authenticated principal of request bound to `auth` tagged input fields.
+func (m *Mux) WithPrincipal(extractor func(r *http.Request) (any, error)) {
+	m.binder.Principal = func(c echo.Context) (any, error) {
+		return extractor(c.Request())
+	}
+}
+
+// WithOptions applies operation options to every Interactor registered afterwards.
+func (m *Mux) WithOptions(ops ...option) {
+	m.ops = append(m.ops, ops...)
+}
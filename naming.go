@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// named is implemented by Interactors of NewHandler and NewUseCase.
+type named interface {
+	Name() string
+}
+
+// WithOperationID sets operationId instead of the one derived from handler name, it must be unique,
+// registration of a duplicate one panics.
+func WithOperationID(id string) option {
+	return operationOption(func(op *operation) {
+		op.SetID(id)
+	})
+}
+
+// generatedName matches names of functions that do not describe operation, e.g. closures.
+var generatedName = regexp.MustCompile(`^(func\d+|main|init)$|[^\pL\pN_]`)
+
+// identify sets operationId of op: the one of WithOperationID, or name of the function creating
+// Interactor h. Duplicate operationId of WithOperationID panics, derived name taken by another
+// operation is logged and falls back to method and path, e.g. `getUsersId`, numbered if it is
+// taken as well, e.g. `getUsersId2`. Unexported input and output types are named after
+// operationId in components, e.g. `LoginInput` and `LoginOutput`.
+func (d *document) identify(op *operation, h Interactor) {
+	fallback := camel(pascal(strings.ToLower(op.method) + " " + op.path))
+	var id string
+	if spec := op.spec().ID; spec != nil && *spec != "" {
+		id = *spec
+		if d.ids[id] {
+			panic(fmt.Sprintf("rest: %s %s: duplicate operationId %q", op.method, op.path, id))
+		}
+	} else {
+		id = fallback
+		if n, ok := h.(named); ok && n.Name() != "" && !generatedName.MatchString(n.Name()) {
+			id = camel(n.Name())
+		}
+		if want := id; d.ids[want] {
+			id = fallback
+			for i := 2; d.ids[id]; i++ {
+				id = fmt.Sprintf("%s%d", fallback, i)
+			}
+			d.logger.Warn("add operation", "method", op.method, "path", op.path,
+				"error", fmt.Errorf("operationId %q is taken, %q is used", want, id))
+		}
+	}
+	d.ids[id] = true
+	op.SetID(id)
+
+	for _, v := range []any{h.Input(), h.Output()} {
+		t := reflect.TypeOf(v).Elem()
+		if t.PkgPath() == "" || t.Name() == "" || !unicode.IsLower([]rune(t.Name())[0]) {
+			continue
+		}
+		if _, ok := d.defNames[t]; !ok {
+			d.defNames[t] = pascal(id) + pascal(t.Name())
+		}
+	}
+}
+
+// defName names types of defNames in components.
+func (d *document) defName(t reflect.Type, defaultDefName string) string {
+	if name, ok := d.defNames[t]; ok {
+		return name
+	}
+	return defaultDefName
+}
+
+// pascal joins words of s in PascalCase, e.g. `get /users/{id}` is `GetUsersId`.
+func pascal(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// camel converts PascalCase s to camelCase.
+func camel(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func listUsers(ops ...option) Interactor {
+	return NewHandler(func(c echo.Context, in struct{}, out *[]string) error {
+		return nil
+	}, ops...)
+}
+
+func TestIdentify(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(WithMiddleware(), WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	s.GET("/users", listUsers())
+	s.GET("/accounts", listUsers())
+	s.GET("/items", listUsers(WithOperationID("getOrders")))
+	s.GET("/orders", listUsers())
+	s.POST("/users", listUsers())
+
+	var ids []string
+	for _, op := range s.Operations() {
+		ids = append(ids, op.ID)
+	}
+	want := []string{"listUsers", "getAccounts", "getOrders", "getOrders2", "postUsers"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %q, want %q", ids, want)
+	}
+	for _, taken := range []string{`\"listUsers\" is taken, \"getAccounts\" is used`, `\"listUsers\" is taken, \"getOrders2\" is used`} {
+		if !strings.Contains(buf.String(), taken) {
+			t.Errorf("log has no %s: %s", taken, buf.String())
+		}
+	}
+}
+
+func TestIdentifyDuplicate(t *testing.T) {
+	s := New(WithMiddleware())
+	s.GET("/users", listUsers())
+	defer func() {
+		if r := recover(); r != `rest: GET /accounts: duplicate operationId "listUsers"` {
+			t.Errorf("got panic %v", r)
+		}
+	}()
+	s.GET("/accounts", listUsers(WithOperationID("listUsers")))
+}
@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mcuadros/go-defaults"
+	"github.com/swaggest/openapi-go"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// Phases of request pipeline, the failed one is recorded in echo.Context under phaseKey.
+const (
+	phaseKey         = "rest.phase"
+	phaseObserverKey = "rest.phaseObserver"
+	phaseBind        = "bind"
+	phaseValidate    = "validate"
+	phaseInteract    = "interact"
+	phaseEncode      = "encode"
+)
+
+// Operation describes a registered Interactor.
+type Operation struct {
+	// ID is operationId of spec, see WithOperationID.
+	ID     string
+	Method string
+	// Path is documented path relative to base URL, e.g. `/users/{id}`.
+	Path    string
+	Summary string
+	// Input and Output are pointers to new input and output of Interactor.
+	Input  any
+	Output any
+	// Version of API the operation is served in, see WithVersions.
+	Version string
+	// Responses are documented statuses in order, e.g. `200`, `4XX` or `default`.
+	Responses []string
+}
+
+// operation holds documentation and runtime settings of a registered Interactor.
+type operation struct {
+	openapi.OperationContext
+	method         string
+	path           string
+	interactor     Interactor
+	binder         *CustomBinder
+	validator      echo.Validator
+	maxBodySize    int64
+	readTimeout    time.Duration
+	handlerTimeout time.Duration
+	examples       []namedExample
+	deprecation    *deprecation
+	// versions is range of versions, see WithVersionRange.
+	versions [2]string
+	document *document
+	// mock answers with examples instead of Interactor.
+	mock *mocker
+}
+
+// spec returns OpenAPI operation for customization.
+func (op *operation) spec() *openapi3.Operation {
+	return op.OperationContext.(openapi3.OperationExposer).Operation()
+}
+
+func (op *operation) describe() Operation {
+	o := op.spec()
+	res := Operation{Method: op.method, Path: op.path, Input: op.interactor.Input(), Output: op.interactor.Output()}
+	if o.ID != nil {
+		res.ID = *o.ID
+	}
+	if o.Summary != nil {
+		res.Summary = *o.Summary
+	}
+	for status := range o.Responses.MapOfResponseOrRefValues {
+		res.Responses = append(res.Responses, status)
+	}
+	sort.Strings(res.Responses)
+	return res
+}
+
+// id returns operationId of spec, method and path if it is not set.
+func (op *operation) id() string {
+	if id := op.spec().ID; id != nil && *id != "" {
+		return *id
+	}
+	return op.method + " " + op.path
+}
+
+// limit applies body size limit and timeouts of operation to request.
+func (op *operation) limit(c echo.Context) (context.CancelFunc, error) {
+	req := c.Request()
+	if op.maxBodySize > 0 {
+		if req.ContentLength > op.maxBodySize {
+			return nil, &http.MaxBytesError{Limit: op.maxBodySize}
+		}
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, op.maxBodySize)
+	}
+	if op.readTimeout > 0 {
+		// Not every ResponseWriter supports deadlines, the limit is best effort then.
+		_ = http.NewResponseController(c.Response()).SetReadDeadline(time.Now().Add(op.readTimeout))
+	}
+	if op.handlerTimeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), op.handlerTimeout)
+		c.SetRequest(req.WithContext(ctx))
+		return cancel, nil
+	}
+	return func() {}, nil
+}
+
+// phase runs step of request pipeline, a failed step is recorded in echo.Context.
+// Step is observed by PhaseObserver of request if any.
+func phase(c echo.Context, name string, step func() error) error {
+	done := func(error) {}
+	if observe, ok := c.Get(phaseObserverKey).(PhaseObserver); ok {
+		done = observe(c, name)
+	}
+	err := step()
+	if err != nil {
+		c.Set(phaseKey, name)
+	}
+	done(err)
+	return err
+}
+
+// handle serves request with Interactor: input is bound and validated, output is encoded.
+func (op *operation) handle(c echo.Context) error {
+	c.Set(operationKey, op)
+	if op.deprecation != nil {
+		op.deprecation.apply(c, op)
+	}
+	cancel, err := op.limit(c)
+	if err != nil {
+		c.Set(phaseKey, phaseBind)
+		return err
+	}
+	defer cancel()
+	h := op.interactor
+	in := h.Input()
+	err = phase(c, phaseBind, func() error {
+		defaults.SetDefaults(in)
+		return op.binder.Bind(in, c)
+	})
+	if err != nil {
+		return err
+	}
+	c.Set(inputKey, in)
+	if err := phase(c, phaseValidate, func() error { return op.validator.Validate(in) }); err != nil {
+		return err
+	}
+	if op.mock != nil {
+		op.mock.respond(c.Response(), c.Request(), op.spec())
+		return nil
+	}
+	c.SetRequest(c.Request().WithContext(withRequestScope(c.Request().Context(), newRequestScope(c, op.binder))))
+	out := h.Output()
+	if err := phase(c, phaseInteract, func() error { return h.Interact(c, in, out) }); err != nil {
+		return err
+	}
+	c.Set(outputKey, out)
+	return phase(c, phaseEncode, func() error {
+		setupOutput(c, out)
+		if _, ok := out.(*NoContent); ok {
+			return c.NoContent(http.StatusNoContent)
+		}
+		status := http.StatusOK
+		if o, ok := out.(OutputWithHTTPStatus); ok {
+			status = o.HTTPStatus()
+		}
+		return encodeOutput(c, status, out)
+	})
+}
@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// Opt is an optional and nullable value, it tells an absent field from null and from a set one.
+//
+// Absent parameters and JSON properties leave Opt unset, empty parameters and JSON nulls make it null.
+// Absent values are skipped by validation, Opt is documented as nullable not required property.
+type Opt[T any] struct {
+	value   T
+	present bool
+	null    bool
+}
+
+// NewOpt creates Opt set to value.
+func NewOpt[T any](value T) Opt[T] {
+	return Opt[T]{value: value, present: true}
+}
+
+// NullOpt creates Opt set to null.
+func NullOpt[T any]() Opt[T] {
+	return Opt[T]{present: true, null: true}
+}
+
+// Get returns value and reports whether it is set.
+func (o Opt[T]) Get() (T, bool) {
+	return o.value, o.IsSet()
+}
+
+// Or returns value if it is set, or def otherwise.
+func (o Opt[T]) Or(def T) T {
+	if o.IsSet() {
+		return o.value
+	}
+	return def
+}
+
+// IsPresent reports whether value was received, null included.
+func (o Opt[T]) IsPresent() bool {
+	return o.present
+}
+
+// IsNull reports whether value was received as null.
+func (o Opt[T]) IsNull() bool {
+	return o.present && o.null
+}
+
+// IsSet reports whether value was received and is not null.
+func (o Opt[T]) IsSet() bool {
+	return o.present && !o.null
+}
+
+// UnmarshalParams implements parameter binding.
+func (o *Opt[T]) UnmarshalParams(params []string) error {
+	*o = Opt[T]{present: true}
+	if len(params) == 0 || (len(params) == 1 && params[0] == "") {
+		o.null = true
+		return nil
+	}
+	field := reflect.ValueOf(&o.value).Elem()
+	if ok, err := unmarshalField(field.Kind(), params[0], field); ok {
+		return err
+	}
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(params), len(params))
+		for j, param := range params {
+			if err := setWithProperType(field.Type().Elem().Kind(), param, slice.Index(j)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setWithProperType(field.Kind(), params[0], field)
+}
+
+// UnmarshalParam implements echo.BindUnmarshaler.
+func (o *Opt[T]) UnmarshalParam(param string) error {
+	return o.UnmarshalParams([]string{param})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *Opt[T]) UnmarshalJSON(data []byte) error {
+	*o = Opt[T]{present: true}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.value)
+}
+
+// MarshalJSON implements json.Marshaler, absent value is encoded as null.
+func (o Opt[T]) MarshalJSON() ([]byte, error) {
+	if !o.IsSet() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// JSONSchemaAllOf exposes T to reflector of the document, so T is documented as anywhere else,
+// e.g. a struct as reference to its component.
+func (o Opt[T]) JSONSchemaAllOf() []interface{} {
+	return []interface{}{new(T)}
+}
+
+// PrepareJSONSchema documents Opt as nullable T, a reference stays in allOf as it can not be nullable.
+func (o Opt[T]) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	if len(schema.AllOf) != 1 || schema.AllOf[0].TypeObject == nil {
+		return nil
+	}
+	item := *schema.AllOf[0].TypeObject
+	if item.Ref != nil {
+		item = jsonschema.Schema{AllOf: schema.AllOf}
+	}
+	item.ReflectType, item.Parent = schema.ReflectType, schema.Parent
+	if !item.HasType(jsonschema.Null) {
+		item.AddType(jsonschema.Null)
+	}
+	*schema = item
+	return nil
+}
+
+// InlineJSONSchema keeps Opt out of schema definitions.
+func (o Opt[T]) InlineJSONSchema() {}
+
+func (o Opt[T]) optValue() (any, bool) {
+	if o.null {
+		return nil, o.present
+	}
+	return o.value, o.present
+}
+
+// optional is implemented by Opt.
+type optional interface {
+	optValue() (any, bool)
+}
+
+var paramsUnmarshalerType = reflect.TypeOf((*paramsUnmarshaler)(nil)).Elem()
+
+// documentOpt marks parameters of Opt fields nullable, reflector drops null type of parameters.
+func (d *document) documentOpt(op *operation) {
+	nullable := map[string]bool{}
+	var walk func(typ reflect.Type)
+	walk = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				walk(field.Type)
+				continue
+			}
+			_, opt := reflect.Zero(field.Type).Interface().(optional)
+			if !opt || !reflect.PointerTo(field.Type).Implements(paramsUnmarshalerType) {
+				continue
+			}
+			for _, in := range []ParamIn{ParamInPath, ParamInQuery, ParamInHeader, ParamInCookie} {
+				if name, _, _ := strings.Cut(field.Tag.Get(string(in)), ","); name != "" {
+					nullable[string(in)+" "+name] = true
+				}
+			}
+		}
+	}
+	typ := reflect.TypeOf(op.interactor.Input()).Elem()
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	walk(typ)
+	for _, p := range op.spec().Parameters {
+		if p.Parameter == nil || p.Parameter.Schema == nil || p.Parameter.Schema.Schema == nil {
+			continue
+		}
+		if nullable[string(p.Parameter.In)+" "+p.Parameter.Name] {
+			p.Parameter.Schema.Schema.WithNullable(true)
+		}
+	}
+}
+
+// paramsUnmarshaler binds all values of a parameter.
+type paramsUnmarshaler interface {
+	UnmarshalParams(params []string) error
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+type OptAddress struct {
+	City string `json:"city" minLength:"2"`
+}
+
+type OptMoney struct {
+	cents int64
+}
+
+func TestOptSchema(t *testing.T) {
+	type output struct {
+		Home  OptAddress      `json:"home"`
+		Work  Opt[OptAddress] `json:"work"`
+		Price Opt[OptMoney]   `json:"price"`
+		Name  Opt[string]     `json:"name" minLength:"3"`
+	}
+	var set bool
+	s := New(WithMiddleware(), WithReflector(func(r *openapi3.Reflector) {
+		r.JSONSchemaReflector().AddTypeMapping(OptMoney{}, "")
+	}))
+	s.GET("/users", NewHandler(func(c echo.Context, in struct{}, out *output) error {
+		out.Home = OptAddress{City: "Bergen"}
+		if set {
+			out.Work, out.Name = NewOpt(OptAddress{City: "Oslo"}), NewOpt("name")
+		}
+		return nil
+	}, WithOperationID("getUser")))
+
+	schemas := s.OpenAPI.Components.Schemas.MapOfSchemaOrRefValues
+	properties := schemas["GetUserOutput"].Schema.Properties
+	for name, want := range map[string]string{
+		"home":  `{"$ref":"#/components/schemas/RestOptAddress"}`,
+		"work":  `{"allOf":[{"$ref":"#/components/schemas/RestOptAddress"}],"nullable":true}`,
+		"price": `{"type":"string","nullable":true}`,
+		"name":  `{"minLength":3,"type":"string","nullable":true}`,
+	} {
+		got, err := json.Marshal(properties[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("got %s schema %s, want %s", name, got, want)
+		}
+	}
+	if _, ok := schemas["RestOptOptAddress"]; ok {
+		t.Error("got component of Opt")
+	}
+
+	s.ValidateResponses(func(c echo.Context, err *ContractError) error { return err })
+	for _, set = range []bool{false, true} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("got status %d with Opt set %v: %s", rec.Code, set, rec.Body)
+		}
+	}
+}
@@ -2,12 +2,24 @@ package rest
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/swaggest/openapi-go"
+	"github.com/swaggest/openapi-go/openapi3"
 )
 
 type option func(oc openapi.OperationContext)
 
+// operationOption adapts f to option, options of Interactors are applied to operation.
+func operationOption(f func(op *operation)) option {
+	return func(oc openapi.OperationContext) {
+		if op, ok := oc.(*operation); ok {
+			f(op)
+		}
+	}
+}
+
 func WithSummary(val string) option {
 	return func(oc openapi.OperationContext) {
 		oc.SetSummary(val)
@@ -24,9 +36,60 @@ func WithTags(val ...string) option {
 		oc.SetTags(val...)
 	}
 }
+
 func WithSecurity(key string) option {
 	return func(oc openapi.OperationContext) {
 		oc.AddSecurity(key)
 		oc.AddRespStructure(new(ErrResponse), openapi.WithHTTPStatus(http.StatusUnauthorized))
 	}
 }
+
+// WithExternalDocs links external documentation of operation.
+func WithExternalDocs(url, description string) option {
+	return operationOption(func(op *operation) {
+		docs := openapi3.ExternalDocumentation{URL: url}
+		if description != "" {
+			docs.Description = &description
+		}
+		op.spec().WithExternalDocs(docs)
+	})
+}
+
+// WithExtension sets vendor extension of operation, `x-` prefix is added to name if missing.
+func WithExtension(name string, value any) option {
+	return operationOption(func(op *operation) {
+		setExtension(op.spec(), name, value)
+	})
+}
+
+func setExtension(spec *openapi3.Operation, name string, value any) {
+	if !strings.HasPrefix(name, "x-") {
+		name = "x-" + name
+	}
+	if spec.MapOfAnything == nil {
+		spec.MapOfAnything = map[string]any{}
+	}
+	spec.MapOfAnything[name] = value
+}
+
+// WithMaxBodySize limits request body to n bytes, larger requests fail with 413 Request Entity Too Large.
+func WithMaxBodySize(n int64) option {
+	return operationOption(func(op *operation) {
+		op.maxBodySize = n
+	})
+}
+
+// WithReadTimeout limits the time to read request body.
+func WithReadTimeout(d time.Duration) option {
+	return operationOption(func(op *operation) {
+		op.readTimeout = d
+	})
+}
+
+// WithHandlerTimeout sets deadline of request context passed to Interactor, errors wrapping
+// context.DeadlineExceeded are responded with 504 Gateway Timeout.
+func WithHandlerTimeout(d time.Duration) option {
+	return operationOption(func(op *operation) {
+		op.handlerTimeout = d
+	})
+}
@@ -0,0 +1,622 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	gojsonschema "github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/swaggest/jsonschema-go"
+)
+
+const (
+	// MIMEApplicationMergePatchJSON is JSON Merge Patch media type, see RFC 7396.
+	MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+	// MIMEApplicationJSONPatchJSON is JSON Patch media type, see RFC 6902.
+	MIMEApplicationJSONPatchJSON = "application/json-patch+json"
+)
+
+// PatchOperation is a single JSON Patch operation.
+type PatchOperation struct {
+	Op    string `json:"op" required:"true" enum:"add,remove,replace,move,copy,test"`
+	Path  string `json:"path" required:"true" description:"JSON Pointer to the target location."`
+	From  string `json:"from,omitempty" description:"JSON Pointer to the source location of move and copy."`
+	Value any    `json:"value,omitempty"`
+	// hasValue reports whether value member was received, it may be null.
+	hasValue bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it records presence of value member.
+func (op *PatchOperation) UnmarshalJSON(data []byte) error {
+	type plain PatchOperation
+	if err := json.Unmarshal(data, (*plain)(op)); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	_, op.hasValue = members["value"]
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, null value of add, replace and test is sent as null.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type plain PatchOperation
+	if op.Value != nil || !valueOperation(op.Op) {
+		return json.Marshal(plain(op))
+	}
+	return json.Marshal(struct {
+		plain
+		Value any `json:"value"`
+	}{plain: plain(op)})
+}
+
+// valueOperation reports whether operation op requires value member.
+func valueOperation(op string) bool {
+	return op == "add" || op == "replace" || op == "test"
+}
+
+// PatchError reports a patch that can not be applied.
+type PatchError struct {
+	Op      string
+	Path    string
+	Message string
+}
+
+// Error implements error.
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("patch %s: %s", e.Path, e.Message)
+	}
+	return fmt.Sprintf("patch %s %s: %s", e.Op, e.Path, e.Message)
+}
+
+// HTTPStatus returns HTTP status code.
+func (e *PatchError) HTTPStatus() int {
+	if e.Op == "test" {
+		return http.StatusConflict
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// Patch receives PATCH request body as `application/merge-patch+json` (also accepted
+// as `application/json`) or `application/json-patch+json`, it keeps track of
+// the fields that were present and applies onto an existing value of T.
+//
+// Patch field must not have `json` tag, request body is bound to it as a whole.
+type Patch[T any] struct {
+	mediaType string
+	merge     any
+	ops       []PatchOperation
+}
+
+// MediaType returns content type of received patch.
+func (p Patch[T]) MediaType() string {
+	return p.mediaType
+}
+
+// Operations returns JSON Patch operations, it is empty for JSON Merge Patch.
+func (p Patch[T]) Operations() []PatchOperation {
+	return p.ops
+}
+
+// Has reports whether value at JSON Pointer path (e.g. `/address/city`) is changed by the patch,
+// a leading slash may be omitted for top-level fields.
+func (p Patch[T]) Has(path string) bool {
+	tokens := pointerTokens(path)
+	if p.mediaType == MIMEApplicationJSONPatchJSON {
+		for _, op := range p.ops {
+			if tokensOverlap(tokens, pointerTokens(op.Path)) {
+				return true
+			}
+			if op.Op == "move" && tokensOverlap(tokens, pointerTokens(op.From)) {
+				return true
+			}
+		}
+		return false
+	}
+	doc := p.merge
+	for _, token := range tokens {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			// Parent value is replaced or removed as a whole.
+			return true
+		}
+		if doc, ok = m[token]; !ok {
+			return false
+		}
+	}
+	return p.merge != nil
+}
+
+// IsNull reports whether JSON Merge Patch explicitly sets value at path to null.
+func (p Patch[T]) IsNull(path string) bool {
+	doc := p.merge
+	for _, token := range pointerTokens(path) {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return false
+		}
+		if doc, ok = m[token]; !ok {
+			return false
+		}
+	}
+	return p.merge != nil && doc == nil
+}
+
+// Apply applies the patch onto v, patched value is validated against schema of T and
+// is not applied if it is invalid.
+func (p Patch[T]) Apply(v *T) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeDocument(b)
+	if err != nil {
+		return err
+	}
+	switch p.mediaType {
+	case MIMEApplicationJSONPatchJSON:
+		for _, op := range p.ops {
+			if doc, err = applyOperation(doc, op); err != nil {
+				return err
+			}
+		}
+	case "":
+		return nil
+	default:
+		doc = mergePatch(doc, p.merge)
+	}
+	if err := validatePatch(reflect.TypeOf(v).Elem(), doc, false); err != nil {
+		var verr *gojsonschema.ValidationError
+		if errors.As(err, &verr) {
+			return &ValidatorError{http.StatusUnprocessableEntity, ParamInBody, verr}
+		}
+		return err
+	}
+	if b, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	var res T
+	if err := json.Unmarshal(b, &res); err != nil {
+		return &PatchError{Message: err.Error()}
+	}
+	*v = res
+	return nil
+}
+
+func (p *Patch[T]) setPatch(mediaType string, body []byte) error {
+	p.mediaType = mediaType
+	if mediaType == MIMEApplicationJSONPatchJSON {
+		if err := json.Unmarshal(body, &p.ops); err != nil {
+			return err
+		}
+		for _, op := range p.ops {
+			if err := checkOperation(op); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// Reject values that do not fit T before they reach the handler.
+	if err := json.Unmarshal(body, new(T)); err != nil {
+		return err
+	}
+	doc, err := decodeDocument(body)
+	if err != nil {
+		return err
+	}
+	if err := validatePatch(reflect.TypeOf(new(T)).Elem(), doc, true); err != nil {
+		var verr *gojsonschema.ValidationError
+		if errors.As(err, &verr) {
+			return &ValidatorError{http.StatusBadRequest, ParamInBody, verr}
+		}
+		return err
+	}
+	p.merge = doc
+	return nil
+}
+
+func (p *Patch[T]) patchTarget() any {
+	return new(T)
+}
+
+// patcher is implemented by Patch fields of input.
+type patcher interface {
+	setPatch(mediaType string, body []byte) error
+	patchTarget() any
+}
+
+// patchField returns the Patch field of struct value val.
+func patchField(val reflect.Value) (patcher, bool) {
+	if val.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		if !field.CanAddr() || !field.CanInterface() {
+			continue
+		}
+		if p, ok := field.Addr().Interface().(patcher); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// patchSchemaKey identifies compiled schema of patch target, partial schema validates
+// JSON Merge Patch documents.
+type patchSchemaKey struct {
+	t       reflect.Type
+	partial bool
+}
+
+// patchSchemas caches compiled schemas by patchSchemaKey.
+var patchSchemas sync.Map
+
+// compilePatch compiles schemas validating patches of type t, operations compile them when
+// registered.
+func compilePatch(t reflect.Type) error {
+	for _, partial := range []bool{false, true} {
+		if _, err := patchSchema(t, partial); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchSchema returns compiled schema of type t, see patchSchemaKey.
+func patchSchema(t reflect.Type, partial bool) (*gojsonschema.Schema, error) {
+	key := patchSchemaKey{t: t, partial: partial}
+	if schema, ok := patchSchemas.Load(key); ok {
+		return schema.(*gojsonschema.Schema), nil
+	}
+	compiled, err := compilePatchSchema(t, partial)
+	if err != nil {
+		return nil, fmt.Errorf("compile patch schema of %s: %w", t, err)
+	}
+	schema, _ := patchSchemas.LoadOrStore(key, compiled)
+	return schema.(*gojsonschema.Schema), nil
+}
+
+// validatePatch validates decoded JSON document against schema of type t, it fails with
+// *gojsonschema.ValidationError. Partial validation ignores required properties and null
+// values, which remove values in JSON Merge Patch.
+func validatePatch(t reflect.Type, doc any, partial bool) error {
+	schema, err := patchSchema(t, partial)
+	if err != nil {
+		return err
+	}
+	if partial {
+		doc = withoutNulls(doc)
+	}
+	return schema.Validate(doc)
+}
+
+func compilePatchSchema(t reflect.Type, partial bool) (*gojsonschema.Schema, error) {
+	reflector := jsonschema.Reflector{}
+	schema, err := reflector.Reflect(reflect.New(t).Elem().Interface())
+	if err != nil {
+		return nil, err
+	}
+	b, err := schema.JSONSchemaBytes()
+	if err != nil {
+		return nil, err
+	}
+	if partial {
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(withoutRequired(v)); err != nil {
+			return nil, err
+		}
+	}
+	return gojsonschema.CompileString("patch.json", string(b))
+}
+
+// withoutRequired removes required properties of all schemas of JSON schema document v.
+func withoutRequired(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if k == "required" {
+				if _, ok := item.([]any); ok {
+					delete(v, k)
+					continue
+				}
+			}
+			v[k] = withoutRequired(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = withoutRequired(item)
+		}
+	}
+	return v
+}
+
+// withoutNulls returns copy of decoded JSON document without null object members.
+func withoutNulls(doc any) any {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return doc
+	}
+	res := make(map[string]any, len(m))
+	for k, v := range m {
+		if v != nil {
+			res[k] = withoutNulls(v)
+		}
+	}
+	return res
+}
+
+func decodeDocument(b []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var doc any
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergePatch implements RFC 7396 MergePatch.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func checkOperation(op PatchOperation) error {
+	switch op.Op {
+	case "add", "remove", "replace", "move", "copy", "test":
+	default:
+		return fmt.Errorf("unknown patch operation %q", op.Op)
+	}
+	if op.Path != "" && !strings.HasPrefix(op.Path, "/") {
+		return fmt.Errorf("invalid patch path %q", op.Path)
+	}
+	if (op.Op == "move" || op.Op == "copy") && op.From != "" && !strings.HasPrefix(op.From, "/") {
+		return fmt.Errorf("invalid patch from %q", op.From)
+	}
+	if valueOperation(op.Op) && !op.hasValue {
+		return fmt.Errorf("patch operation %q has no value", op.Op)
+	}
+	return nil
+}
+
+// applyOperation implements RFC 6902 operations on decoded JSON document.
+func applyOperation(doc any, op PatchOperation) (any, error) {
+	path := pointerTokens(op.Path)
+	fail := func(msg string) error {
+		return &PatchError{Op: op.Op, Path: op.Path, Message: msg}
+	}
+	switch op.Op {
+	case "add", "replace":
+		value, err := normalizeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return setPointer(doc, path, value, op.Op == "replace", fail)
+	case "remove":
+		doc, _, err := removePointer(doc, path, fail)
+		return doc, err
+	case "move":
+		from := pointerTokens(op.From)
+		if len(from) < len(path) && tokensOverlap(from, path) {
+			return nil, fail("can not move value into its own child")
+		}
+		doc, value, err := removePointer(doc, from, fail)
+		if err != nil {
+			return nil, err
+		}
+		return setPointer(doc, path, value, false, fail)
+	case "copy":
+		value, ok := getPointer(doc, pointerTokens(op.From))
+		if !ok {
+			return nil, fail("from location does not exist")
+		}
+		value, err := normalizeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return setPointer(doc, path, value, false, fail)
+	case "test":
+		value, ok := getPointer(doc, path)
+		if !ok {
+			return nil, fail("location does not exist")
+		}
+		expected, err := normalizeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, expected) {
+			return nil, fail("value does not match")
+		}
+		return doc, nil
+	}
+	return nil, fail("unknown operation")
+}
+
+// normalizeValue converts value to the form produced by decodeDocument.
+func normalizeValue(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocument(b)
+}
+
+// jsonEqual reports whether decoded JSON values are equal, numbers are compared by value,
+// e.g. `1` equals `1.0`.
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okx := new(big.Rat).SetString(string(a))
+		y, oky := new(big.Rat).SetString(string(b))
+		return okx && oky && x.Cmp(y) == 0
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// arrayIndex parses array index token of JSON Pointer, leading zeros and signs are invalid.
+func arrayIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || token[0] < '0' || token[0] > '9' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	return i, err == nil
+}
+
+func pointerTokens(path string) []string {
+	if path == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+func tokensOverlap(a, b []string) bool {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func getPointer(doc any, path []string) (any, bool) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			doc = v
+		case []any:
+			i, ok := arrayIndex(token)
+			if !ok || i >= len(node) {
+				return nil, false
+			}
+			doc = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func setPointer(doc any, path []string, value any, replace bool, fail func(string) error) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, ok := getPointer(doc, path[:len(path)-1])
+	if !ok {
+		return nil, fail("parent location does not exist")
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		if _, ok := node[token]; replace && !ok {
+			return nil, fail("location does not exist")
+		}
+		node[token] = value
+		return doc, nil
+	case []any:
+		i := len(node)
+		if token != "-" {
+			var ok bool
+			if i, ok = arrayIndex(token); !ok || i > len(node) || (replace && i == len(node)) {
+				return nil, fail("invalid array index")
+			}
+		} else if replace {
+			return nil, fail("invalid array index")
+		}
+		if replace {
+			node[i] = value
+		} else {
+			node = append(node[:i], append([]any{value}, node[i:]...)...)
+		}
+		return setPointer(doc, path[:len(path)-1], node, true, fail)
+	}
+	return nil, fail("parent is not a container")
+}
+
+func removePointer(doc any, path []string, fail func(string) error) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fail("can not remove document root")
+	}
+	value, ok := getPointer(doc, path)
+	if !ok {
+		return nil, nil, fail("location does not exist")
+	}
+	parent, _ := getPointer(doc, path[:len(path)-1])
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		delete(node, token)
+		return doc, value, nil
+	case []any:
+		i, _ := arrayIndex(token)
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err := setPointer(doc, path[:len(path)-1], node, true, fail)
+		return doc, value, err
+	}
+	return nil, nil, fail("parent is not a container")
+}
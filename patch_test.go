@@ -0,0 +1,239 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type patchUser struct {
+	Name  string   `json:"name" minLength:"3" required:"true"`
+	Email string   `json:"email,omitempty" format:"email"`
+	Age   int      `json:"age,omitempty" minimum:"0"`
+	Tags  []string `json:"tags,omitempty"`
+	Meta  *struct {
+		Score float64 `json:"score"`
+		Level any     `json:"level,omitempty"`
+	} `json:"meta,omitempty"`
+}
+
+func newPatch(t *testing.T, mediaType, body string) Patch[patchUser] {
+	t.Helper()
+	var p Patch[patchUser]
+	if err := p.setPatch(mediaType, []byte(body)); err != nil {
+		t.Fatalf("setPatch(%s): %v", body, err)
+	}
+	return p
+}
+
+func applyPatch(t *testing.T, mediaType, target, body string) (patchUser, error) {
+	t.Helper()
+	var v patchUser
+	if err := json.Unmarshal([]byte(target), &v); err != nil {
+		t.Fatal(err)
+	}
+	p := newPatch(t, mediaType, body)
+	err := p.Apply(&v)
+	return v, err
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name, target, patch, want string
+	}{
+		{"replace", `{"name":"alice","age":3}`, `{"age":4}`, `{"name":"alice","age":4}`},
+		{"remove with null", `{"name":"alice","email":"a@b.c"}`, `{"email":null}`, `{"name":"alice"}`},
+		{"replace array", `{"name":"alice","tags":["a","b"]}`, `{"tags":["c"]}`, `{"name":"alice","tags":["c"]}`},
+		{"create object", `{"name":"alice"}`, `{"meta":{"score":1.5}}`, `{"name":"alice","meta":{"score":1.5}}`},
+		{"nested null", `{"name":"alice","meta":{"score":1,"level":"x"}}`, `{"meta":{"level":null}}`, `{"name":"alice","meta":{"score":1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(t, MIMEApplicationMergePatchJSON, tt.target, tt.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want patchUser
+			json.Unmarshal([]byte(tt.want), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergePatchValidation(t *testing.T) {
+	var p Patch[patchUser]
+	err := p.setPatch(MIMEApplicationMergePatchJSON, []byte(`{"name":"x"}`))
+	var verr *ValidatorError
+	if !errors.As(err, &verr) {
+		t.Fatalf("short name: got %v, want ValidatorError", err)
+	}
+	if _, ok := verr.Fields()["json:name"]; !ok {
+		t.Errorf("fields %v do not report name", verr.Fields())
+	}
+
+	// Required fields may be left out of a merge patch and removed ones fail on Apply.
+	newPatch(t, MIMEApplicationMergePatchJSON, `{"age":4}`)
+	if _, err := applyPatch(t, MIMEApplicationMergePatchJSON, `{"name":"alice"}`, `{"name":null}`); !errors.As(err, &verr) || verr.HTTPStatus() != http.StatusUnprocessableEntity {
+		t.Errorf("removed required name: got %v, want 422 ValidatorError", err)
+	}
+}
+
+func TestMergePatchHas(t *testing.T) {
+	p := newPatch(t, MIMEApplicationMergePatchJSON, `{"email":null,"meta":{"score":2}}`)
+	for path, want := range map[string]bool{
+		"/email": true, "email": true, "/meta/score": true, "/meta/level": false, "/name": false,
+	} {
+		if got := p.Has(path); got != want {
+			t.Errorf("Has(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if !p.IsNull("/email") || p.IsNull("/meta") {
+		t.Errorf("IsNull reports wrong values")
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	target := `{"name":"alice","age":1,"tags":["a","b"],"meta":{"score":1.0}}`
+	tests := []struct {
+		name, patch, want string
+		status            int
+	}{
+		{"add", `[{"op":"add","path":"/email","value":"a@b.c"}]`, `{"name":"alice","email":"a@b.c","age":1,"tags":["a","b"],"meta":{"score":1}}`, 0},
+		{"append with dash", `[{"op":"add","path":"/tags/-","value":"c"}]`, `{"name":"alice","age":1,"tags":["a","b","c"],"meta":{"score":1}}`, 0},
+		{"insert", `[{"op":"add","path":"/tags/0","value":"c"}]`, `{"name":"alice","age":1,"tags":["c","a","b"],"meta":{"score":1}}`, 0},
+		{"insert at end", `[{"op":"add","path":"/tags/2","value":"c"}]`, `{"name":"alice","age":1,"tags":["a","b","c"],"meta":{"score":1}}`, 0},
+		{"remove item", `[{"op":"remove","path":"/tags/0"}]`, `{"name":"alice","age":1,"tags":["b"],"meta":{"score":1}}`, 0},
+		{"replace", `[{"op":"replace","path":"/age","value":2}]`, `{"name":"alice","age":2,"tags":["a","b"],"meta":{"score":1}}`, 0},
+		{"move", `[{"op":"move","from":"/tags/0","path":"/tags/1"}]`, `{"name":"alice","age":1,"tags":["b","a"],"meta":{"score":1}}`, 0},
+		{"copy", `[{"op":"copy","from":"/tags/0","path":"/tags/-"}]`, `{"name":"alice","age":1,"tags":["a","b","a"],"meta":{"score":1}}`, 0},
+		{"copy into descendant", `[{"op":"copy","from":"/meta","path":"/meta/level"}]`, `{"name":"alice","age":1,"tags":["a","b"],"meta":{"score":1,"level":{"score":1}}}`, 0},
+		{"test integer and float", `[{"op":"test","path":"/age","value":1.0},{"op":"test","path":"/meta/score","value":1}]`, target, 0},
+		{"test array", `[{"op":"test","path":"/tags","value":["a","b"]}]`, target, 0},
+		{"test mismatch", `[{"op":"test","path":"/age","value":2}]`, "", http.StatusConflict},
+		{"test mismatch type", `[{"op":"test","path":"/age","value":"1"}]`, "", http.StatusConflict},
+		{"test dash", `[{"op":"test","path":"/tags/-","value":"b"}]`, "", http.StatusConflict},
+		{"move into descendant", `[{"op":"move","from":"/meta","path":"/meta/level"}]`, "", http.StatusUnprocessableEntity},
+		{"replace dash", `[{"op":"replace","path":"/tags/-","value":"c"}]`, "", http.StatusUnprocessableEntity},
+		{"remove dash", `[{"op":"remove","path":"/tags/-"}]`, "", http.StatusUnprocessableEntity},
+		{"index out of range", `[{"op":"add","path":"/tags/3","value":"c"}]`, "", http.StatusUnprocessableEntity},
+		{"leading zero index", `[{"op":"replace","path":"/tags/01","value":"c"}]`, "", http.StatusUnprocessableEntity},
+		{"missing parent", `[{"op":"add","path":"/meta/a/b","value":1}]`, "", http.StatusUnprocessableEntity},
+		{"invalid result", `[{"op":"replace","path":"/name","value":"x"}]`, "", http.StatusUnprocessableEntity},
+		{"remove required", `[{"op":"remove","path":"/name"}]`, "", http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(t, MIMEApplicationJSONPatchJSON, target, tt.patch)
+			if tt.status != 0 {
+				var herr ErrWithHTTPStatus
+				if !errors.As(err, &herr) || herr.HTTPStatus() != tt.status {
+					t.Fatalf("got %v, want error with status %d", err, tt.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want patchUser
+			json.Unmarshal([]byte(tt.want), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestJSONPatchHas(t *testing.T) {
+	p := newPatch(t, MIMEApplicationJSONPatchJSON, `[{"op":"move","from":"/email","path":"/meta/level"}]`)
+	for path, want := range map[string]bool{"/email": true, "/meta": true, "/meta/level/x": true, "/name": false} {
+		if got := p.Has(path); got != want {
+			t.Errorf("Has(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestPatchBinding(t *testing.T) {
+	type input struct {
+		ID    int `path:"id"`
+		Patch Patch[patchUser]
+	}
+	s := NewService()
+	s.PATCH("/users/{id}", NewHandler(func(c echo.Context, in input, out *patchUser) error {
+		*out = patchUser{Name: "alice"}
+		return in.Patch.Apply(out)
+	}))
+	tests := []struct {
+		contentType, body string
+		status            int
+	}{
+		{MIMEApplicationMergePatchJSON, `{"age":3}`, http.StatusOK},
+		{echo.MIMEApplicationJSON, `{"age":3}`, http.StatusOK},
+		{MIMEApplicationMergePatchJSON, `{"name":"x"}`, http.StatusBadRequest},
+		{MIMEApplicationMergePatchJSON, `{"age":-1}`, http.StatusBadRequest},
+		{MIMEApplicationMergePatchJSON, `{"age":"3"}`, http.StatusBadRequest},
+		{MIMEApplicationJSONPatchJSON, `[{"op":"replace","path":"/name","value":"bob"}]`, http.StatusOK},
+		{MIMEApplicationJSONPatchJSON, `[{"op":"replace","path":"/name","value":"x"}]`, http.StatusUnprocessableEntity},
+		{MIMEApplicationJSONPatchJSON, `[{"op":"jump","path":"/name"}]`, http.StatusBadRequest},
+		{MIMEApplicationJSONPatchJSON, `[{"op":"replace","path":"/name"}]`, http.StatusBadRequest},
+		{echo.MIMETextPlain, `{}`, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, tt.contentType)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: got %d %s, want %d", tt.contentType, tt.body, rec.Code, rec.Body, tt.status)
+		}
+	}
+}
+
+func TestJSONPatchValue(t *testing.T) {
+	for _, op := range []string{"add", "replace", "test"} {
+		var p Patch[patchUser]
+		if err := p.setPatch(MIMEApplicationJSONPatchJSON, []byte(`[{"op":"`+op+`","path":"/meta/level"}]`)); err == nil {
+			t.Errorf("%s without value: got no error", op)
+		}
+	}
+	got, err := applyPatch(t, MIMEApplicationJSONPatchJSON, `{"name":"alice","meta":{"score":1,"level":2}}`,
+		`[{"op":"test","path":"/meta/score","value":1},{"op":"replace","path":"/meta/level","value":null}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Meta == nil || got.Meta.Level != nil {
+		t.Errorf("got %+v, want null level", got.Meta)
+	}
+
+	b, err := json.Marshal([]PatchOperation{{Op: "add", Path: "/meta/level"}, {Op: "remove", Path: "/age"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"op":"add","path":"/meta/level","value":null},{"op":"remove","path":"/age"}]`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestPatchSchemaPanics(t *testing.T) {
+	type invalid struct {
+		Code string `json:"code" pattern:"("`
+	}
+	defer func() {
+		if r, _ := recover().(string); !strings.HasPrefix(r, "rest: PATCH /codes/{id}: compile patch schema") {
+			t.Errorf("got panic %q", r)
+		}
+	}()
+	NewService().PATCH("/codes/{id}", NewHandler(func(c echo.Context, in struct {
+		ID    int `path:"id"`
+		Patch Patch[invalid]
+	}, out *invalid) error {
+		return nil
+	}))
+}
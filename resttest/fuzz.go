@@ -0,0 +1,685 @@
+package resttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fourcels/rest"
+	"github.com/fourcels/rest/client"
+)
+
+// Case is a generated input of operation.
+type Case struct {
+	// Name describes the boundary, e.g. `valid` or `query limit below minimum`.
+	Name string
+	// Input is a pointer to input of operation.
+	Input any
+	// Valid reports whether input satisfies constraints of its fields, it must be answered with
+	// 2xx or documented status. Inputs with file or patch bodies, which are not generated, and
+	// with patterns no value is found for are not valid.
+	Valid bool
+	// Raw are parameter values sent instead of values of Input, e.g. `abc` of an integer field,
+	// keyed by location and name, e.g. `query limit`.
+	Raw map[string]string
+}
+
+var (
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+	filePartType   = reflect.TypeOf(rest.FilePart{})
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// locations are tags of input fields, in order of lookup.
+var locations = []string{"path", "query", "header", "cookie", "json", "form", "formData"}
+
+// parameters are locations of fields sent as raw strings.
+var parameters = []string{"path", "query", "header", "cookie"}
+
+// Cases generates valid and near-invalid inputs of operation by `minimum`, `maximum`,
+// `exclusiveMinimum`, `exclusiveMaximum`, `minLength`, `maxLength`, `minItems`, `maxItems`,
+// `pattern` and `enum` tags of input fields: a valid input, then boundary values of every constrained field
+// and values just outside of them. Parameters of numeric, boolean and time fields are sent as
+// raw strings of the wrong type as well.
+func Cases(op rest.Operation) []Case {
+	typ := reflect.TypeOf(op.Input)
+	if typ == nil || typ.Kind() != reflect.Pointer || typ.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	valid := !hasBody(typ.Elem())
+	newInput := func() reflect.Value {
+		in := reflect.New(typ.Elem())
+		if !generate(in.Elem(), "") {
+			valid = false
+		}
+		return in
+	}
+	base := newInput()
+	cases := []Case{{Name: "valid", Input: base.Interface(), Valid: valid}}
+	for _, f := range constrainedFields(typ.Elem(), nil, "") {
+		for _, b := range boundaries(f) {
+			in := newInput()
+			field := fieldByIndex(in.Elem(), f.index)
+			if err := setValue(field, b.value); err != nil {
+				continue
+			}
+			cases = append(cases, Case{Name: f.name + " " + b.name, Input: in.Interface(), Valid: valid && b.valid})
+		}
+	}
+	for _, f := range parameterFields(typ.Elem(), nil) {
+		for _, raw := range rawValues(f.typ) {
+			cases = append(cases, Case{Name: fmt.Sprintf("%s %q", f.name, raw), Input: newInput().Interface(), Raw: map[string]string{f.name: raw}})
+		}
+	}
+	return cases
+}
+
+// Fuzz calls every operation of h with its Cases, the test fails on panics, 5xx responses and
+// statuses not documented by operation. Binding and validation errors, 400 and 422, are
+// allowed for invalid cases as they are not documented.
+func Fuzz(t *testing.T, h Handler, ops ...requestOption) {
+	t.Helper()
+	for _, op := range h.Operations() {
+		op := op
+		t.Run(op.Method+" "+op.Path, func(t *testing.T) {
+			for _, c := range Cases(op) {
+				if err := check(h, op, c, ops); err != nil {
+					t.Errorf("%s: %v", c.Name, err)
+				}
+			}
+		})
+	}
+}
+
+// FuzzOperation fuzzes operation registered for method and pattern with native Go fuzzing.
+// Cases of operation are added to corpus as JSON encoded input, fuzzed inputs are decoded
+// into input of operation and checked as by Fuzz.
+//
+//	func FuzzCreateUser(f *testing.F) {
+//		resttest.FuzzOperation(f, newService(), http.MethodPost, "/users")
+//	}
+func FuzzOperation(f *testing.F, h Handler, method, pattern string, ops ...requestOption) {
+	f.Helper()
+	var op rest.Operation
+	if !registered(h, method, pattern) {
+		f.Fatalf("resttest: operation %s %s is not registered", method, pattern)
+	}
+	for _, o := range h.Operations() {
+		if o.Method == method && o.Path == pattern {
+			op = o
+		}
+	}
+	for _, c := range Cases(op) {
+		if c.Raw != nil {
+			continue
+		}
+		data, err := json.Marshal(c.Input)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		in := reflect.New(reflect.TypeOf(op.Input).Elem())
+		if err := json.Unmarshal(data, in.Interface()); err != nil {
+			return
+		}
+		if err := check(h, op, Case{Input: in.Interface()}, ops); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// check calls operation with case c and returns error on panic, 5xx or undocumented status.
+func check(h Handler, op rest.Operation, c Case, ops []requestOption) (err error) {
+	pattern := op.Path
+	for key, raw := range c.Raw {
+		if name, ok := strings.CutPrefix(key, "path "); ok {
+			pattern = strings.ReplaceAll(pattern, "{"+name+"}", url.PathEscape(raw))
+		}
+	}
+	req, rerr := client.NewRequest(context.Background(), op.Method, "http://example.com"+h.BaseURL()+pattern, c.Input)
+	if rerr != nil || !routable(h.BaseURL()+op.Path, req.URL.Path) {
+		// Input that can not be sent, e.g. empty path parameter, does not reach operation.
+		return nil
+	}
+	for key, raw := range c.Raw {
+		setRaw(req, key, raw)
+	}
+	for _, o := range ops {
+		if err := o(req); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	status := rec.Code
+	switch {
+	case status >= http.StatusInternalServerError:
+		return fmt.Errorf("status %d: %s", status, strings.TrimSpace(rec.Body.String()))
+	case !c.Valid && (status == http.StatusBadRequest || status == http.StatusUnprocessableEntity):
+		return nil
+	case !documented(op.Responses, status):
+		if c.Valid {
+			return fmt.Errorf("valid input got status %d: %s", status, strings.TrimSpace(rec.Body.String()))
+		}
+		return fmt.Errorf("status %d is not documented", status)
+	}
+	return nil
+}
+
+// setRaw sets parameter of req at key, e.g. `query limit`, to raw. Path parameters are set by check.
+func setRaw(req *http.Request, key, raw string) {
+	loc, name, _ := strings.Cut(key, " ")
+	switch loc {
+	case "query":
+		query := req.URL.Query()
+		query.Set(name, raw)
+		req.URL.RawQuery = query.Encode()
+	case "header":
+		req.Header.Set(name, raw)
+	case "cookie":
+		cookies := req.Cookies()
+		req.Header.Del("Cookie")
+		for _, cookie := range cookies {
+			if cookie.Name != name {
+				req.AddCookie(cookie)
+			}
+		}
+		req.AddCookie(&http.Cookie{Name: name, Value: raw})
+	}
+}
+
+// hasBody reports whether typ has fields of file or patch bodies, which are not generated.
+func hasBody(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct && hasBody(ft) {
+			return true
+		}
+		if ft == filePartType || ft == fileHeaderType.Elem() || ft == rawMessageType ||
+			(ft.PkgPath() == optPkg && strings.HasPrefix(ft.Name(), "Patch[")) {
+			return true
+		}
+	}
+	return false
+}
+
+// parameterFields returns fields of typ sent as path, query, header or cookie parameters.
+func parameterFields(typ reflect.Type, index []int) []field {
+	var res []field
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		idx := append(append([]int{}, index...), i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			res = append(res, parameterFields(f.Type, idx)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		for _, loc := range parameters {
+			if n := tagName(f, loc); n != "" {
+				res = append(res, field{name: loc + " " + n, index: idx, tag: f.Tag, typ: f.Type})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// rawValues returns strings not parsed as value of typ, items of slices included.
+func rawValues(typ reflect.Type) []string {
+	typ = valueType(typ)
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return []string{"abc"}
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{"abc", "1.5", "99999999999999999999"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []string{"abc", "-1", "99999999999999999999"}
+	case reflect.Float32, reflect.Float64:
+		return []string{"abc"}
+	case reflect.Bool:
+		return []string{"abc"}
+	}
+	return nil
+}
+
+// documented reports whether status matches one of responses, exactly, by `NXX` range or default.
+func documented(responses []string, status int) bool {
+	code := strconv.Itoa(status)
+	for _, r := range responses {
+		if r == code || r == "default" || strings.EqualFold(r, code[:1]+"XX") {
+			return true
+		}
+	}
+	return false
+}
+
+// routable reports whether path has a non-empty segment for every segment of pattern.
+func routable(pattern, path string) bool {
+	a, b := strings.Split(pattern, "/"), strings.Split(path, "/")
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.HasPrefix(a[i], "{") && b[i] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// field is a constrained field of input.
+type field struct {
+	// name is location and name of field, e.g. `query limit` or `json user.name`.
+	name  string
+	index []int
+	tag   reflect.StructTag
+	typ   reflect.Type
+}
+
+// constrainedFields returns fields of typ with constraint tags, nested structs of JSON body included.
+func constrainedFields(typ reflect.Type, index []int, prefix string) []field {
+	var res []field
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		idx := append(append([]int{}, index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			res = append(res, constrainedFields(ft, idx, prefix)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := prefix
+		if name == "" {
+			for _, loc := range locations {
+				if n := tagName(f, loc); n != "" {
+					name = loc + " " + n
+					break
+				}
+			}
+		} else if n := tagName(f, "json"); n != "" {
+			name += "." + n
+		} else {
+			continue
+		}
+		if name == "" || name == prefix {
+			continue
+		}
+		if ft.Kind() == reflect.Struct && ft != timeType && !isOpt(ft) && strings.HasPrefix(name, "json ") {
+			res = append(res, constrainedFields(ft, idx, name)...)
+			continue
+		}
+		if constrained(f.Tag) {
+			res = append(res, field{name: name, index: idx, tag: f.Tag, typ: f.Type})
+		}
+	}
+	return res
+}
+
+func constrained(tag reflect.StructTag) bool {
+	for _, key := range []string{"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems", "pattern", "enum"} {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type boundary struct {
+	name  string
+	value any
+	valid bool
+}
+
+// boundaries returns values at and just outside of constraints of field.
+func boundaries(f field) []boundary {
+	var res []boundary
+	kind := valueType(f.typ).Kind()
+	if enum, ok := f.tag.Lookup("enum"); ok {
+		for _, v := range strings.Split(enum, ",") {
+			res = append(res, boundary{"enum " + v, v, true})
+		}
+		if kind == reflect.String {
+			res = append(res, boundary{"not in enum", "not-" + strings.Split(enum, ",")[0], false})
+		}
+		return res
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		step := 1.0
+		if kind == reflect.Float32 || kind == reflect.Float64 {
+			step = 0.5
+		}
+		if min, ok := number(f.tag, "minimum"); ok {
+			if f.tag.Get("exclusiveMinimum") == "true" {
+				res = append(res, boundary{"at minimum", min + step, true}, boundary{"at exclusive minimum", min, false})
+			} else {
+				res = append(res, boundary{"at minimum", min, true}, boundary{"below minimum", min - step, false})
+			}
+		}
+		if max, ok := number(f.tag, "maximum"); ok {
+			if f.tag.Get("exclusiveMaximum") == "true" {
+				res = append(res, boundary{"at maximum", max - step, true}, boundary{"at exclusive maximum", max, false})
+			} else {
+				res = append(res, boundary{"at maximum", max, true}, boundary{"above maximum", max + step, false})
+			}
+		}
+	case reflect.String:
+		// Values of length are valid if they match pattern, formats are not generated by length.
+		_, format := f.tag.Lookup("format")
+		if min, ok := number(f.tag, "minLength"); ok {
+			at, valid := text(f, int(min))
+			res = append(res, boundary{"at minLength", at, valid && !format})
+			if min > 0 {
+				below, _ := text(f, int(min)-1)
+				res = append(res, boundary{"below minLength", below, false})
+			}
+		}
+		if max, ok := number(f.tag, "maxLength"); ok {
+			at, valid := text(f, int(max))
+			above, _ := text(f, int(max)+1)
+			res = append(res, boundary{"at maxLength", at, valid && !format}, boundary{"above maxLength", above, false})
+		}
+		if pattern, ok := f.tag.Lookup("pattern"); ok {
+			if re, err := regexp.Compile(pattern); err == nil {
+				for _, v := range []string{"-", " ", "0", "a", "_"} {
+					if !re.MatchString(v) {
+						res = append(res, boundary{"not matching pattern", v, false})
+						break
+					}
+				}
+			}
+		}
+	case reflect.Slice:
+		if min, ok := number(f.tag, "minItems"); ok && min > 0 {
+			res = append(res, boundary{"below minItems", int(min) - 1, false})
+		}
+		if max, ok := number(f.tag, "maxItems"); ok {
+			res = append(res, boundary{"at maxItems", int(max), true}, boundary{"above maxItems", int(max) + 1, false})
+		}
+	}
+	return res
+}
+
+func number(tag reflect.StructTag, key string) (float64, bool) {
+	v, ok := tag.Lookup(key)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	return n, err == nil
+}
+
+// text returns string of length n, taking characters from example of field if any. It reports
+// whether the string matches pattern of field.
+func text(f field, n int) (string, bool) {
+	seeds := []string{"a", "0", "A", "-", "_"}
+	if example := f.tag.Get("example"); example != "" {
+		seeds = append([]string{example}, seeds...)
+	}
+	var re *regexp.Regexp
+	if pattern, ok := f.tag.Lookup("pattern"); ok {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return repeat(seeds[0], n), false
+		}
+	}
+	for _, seed := range seeds {
+		if s := repeat(seed, n); re == nil || re.MatchString(s) {
+			return s, true
+		}
+	}
+	return repeat(seeds[0], n), false
+}
+
+func repeat(seed string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(seed, n/len(seed)+1)[:n]
+}
+
+// generate sets v to a value satisfying constraints of tag, it reports whether one is found.
+func generate(v reflect.Value, tag reflect.StructTag) bool {
+	if v.Type() == fileHeaderType || v.Type() == rawMessageType {
+		return true
+	}
+	if isOpt(v.Type()) {
+		inner := reflect.New(valueType(v.Type())).Elem()
+		ok := generate(inner, tag)
+		setOpt(v, inner.Interface())
+		return ok
+	}
+	if example, ok := tag.Lookup("example"); ok && setValue(v, example) == nil {
+		return true
+	}
+	if def, ok := tag.Lookup("default"); ok && setValue(v, def) == nil {
+		return true
+	}
+	if enum, ok := tag.Lookup("enum"); ok && setValue(v, strings.Split(enum, ",")[0]) == nil {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			v.Set(reflect.New(v.Type().Elem()))
+			return generate(v.Elem(), tag)
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+			return true
+		}
+		valid := true
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() || v.Type().Field(i).Anonymous {
+				if v.Field(i).CanSet() && !generate(v.Field(i), v.Type().Field(i).Tag) {
+					valid = false
+				}
+			}
+		}
+		return valid
+	case reflect.String:
+		n := 1
+		if min, ok := number(tag, "minLength"); ok && int(min) > n {
+			n = int(min)
+		}
+		if max, ok := number(tag, "maxLength"); ok && int(max) < n {
+			n = int(max)
+		}
+		if format := tag.Get("format"); formats[format] != "" {
+			v.SetString(formats[format])
+			return true
+		}
+		s, ok := text(field{tag: tag}, n)
+		v.SetString(s)
+		return ok
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		n := 1.0
+		if min, ok := number(tag, "minimum"); ok {
+			n = min
+			if tag.Get("exclusiveMinimum") == "true" {
+				n++
+			}
+		} else if max, ok := number(tag, "maximum"); ok && max < n {
+			n = max
+			if tag.Get("exclusiveMaximum") == "true" {
+				n--
+			}
+		}
+		_ = setValue(v, n)
+	case reflect.Slice:
+		n := 1
+		if min, ok := number(tag, "minItems"); ok && int(min) > n {
+			n = int(min)
+		}
+		_ = setValue(v, n)
+	}
+	return true
+}
+
+var formats = map[string]string{
+	"email":     "user@example.com",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"uri":       "https://example.com",
+	"ipv4":      "127.0.0.1",
+	"ipv6":      "::1",
+	"hostname":  "example.com",
+}
+
+// setValue sets v to value: string is parsed by kind of v, number is converted, int sets
+// length of slice with generated items.
+func setValue(v reflect.Value, value any) error {
+	if isOpt(v.Type()) {
+		inner := reflect.New(valueType(v.Type())).Elem()
+		if err := setValue(inner, value); err != nil {
+			return err
+		}
+		setOpt(v, inner.Interface())
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), value)
+	}
+	switch value := value.(type) {
+	case string:
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(value)
+			return nil
+		case reflect.Slice:
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+			return setValue(v.Index(0), value)
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			if v.Kind() == reflect.Bool {
+				b, err := strconv.ParseBool(value)
+				v.SetBool(b)
+				return err
+			}
+			return err
+		}
+		return setValue(v, n)
+	case float64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(int64(value))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value < 0 {
+				return fmt.Errorf("resttest: %v overflows %s", value, v.Type())
+			}
+			v.SetUint(uint64(value))
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(value)
+		default:
+			return fmt.Errorf("resttest: can not set %s to number", v.Type())
+		}
+		return nil
+	case int:
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("resttest: can not set %s to %d items", v.Type(), value)
+		}
+		v.Set(reflect.MakeSlice(v.Type(), value, value))
+		for i := 0; i < value; i++ {
+			generate(v.Index(i), "")
+		}
+		return nil
+	}
+	return fmt.Errorf("resttest: can not set %s to %v", v.Type(), value)
+}
+
+// fieldByIndex returns nested field of v, allocating nil pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// isOpt reports whether typ is rest.Opt.
+func isOpt(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == optPkg && strings.HasPrefix(typ.Name(), "Opt[")
+}
+
+var optPkg = reflect.TypeOf(rest.Opt[int]{}).PkgPath()
+
+// valueType returns type of value of rest.Opt, or typ itself.
+func valueType(typ reflect.Type) reflect.Type {
+	if isOpt(typ) {
+		get, _ := typ.MethodByName("Get")
+		return get.Type.Out(0)
+	}
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// setOpt sets rest.Opt v to value.
+func setOpt(v reflect.Value, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	_ = v.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data)
+}
+
+func tagName(f reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+	if name == "-" {
+		return ""
+	}
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,73 @@
+package resttest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fourcels/rest"
+	"github.com/labstack/echo/v4"
+)
+
+type item struct {
+	ID    int            `path:"id" minimum:"1"`
+	Limit rest.Opt[uint] `query:"limit" maximum:"10"`
+	Code  string         `query:"code" pattern:"^[0-9]+$" maxLength:"4"`
+	Flags []bool         `query:"flags"`
+	Token string         `header:"X-Token" minLength:"2"`
+}
+
+func TestCases(t *testing.T) {
+	s := rest.NewService("/api")
+	s.GET("/items/{id}", rest.NewHandler(func(c echo.Context, in item, out *item) error {
+		*out = in
+		return nil
+	}))
+	cases := Cases(s.Operations()[0])
+	raw := map[string]bool{}
+	for _, c := range cases {
+		for key, value := range c.Raw {
+			raw[key+" "+value] = true
+			if c.Valid {
+				t.Errorf("%s: raw case is valid", c.Name)
+			}
+		}
+	}
+	if !cases[0].Valid || cases[0].Input.(*item).Code != "0" {
+		t.Errorf("valid case %+v does not match pattern", cases[0])
+	}
+	for _, key := range []string{"path id abc", "path id 1.5", "query limit -1", "query flags abc"} {
+		if !raw[key] {
+			t.Errorf("no raw case %q in %v", key, raw)
+		}
+	}
+	Fuzz(t, s)
+}
+
+func TestCheck(t *testing.T) {
+	s := rest.NewService("/api")
+	s.GET("/items/{id}", rest.NewHandler(func(c echo.Context, in item, out *item) error {
+		if in.ID == 1 {
+			return rest.HTTPCodeAsError(http.StatusBadRequest)
+		}
+		return nil
+	}))
+	op := s.Operations()[0]
+	in := &item{ID: 1, Code: "1", Token: "ab"}
+	if err := check(s, op, Case{Input: in, Valid: true}, nil); err == nil || !strings.Contains(err.Error(), "valid input got status 400") {
+		t.Errorf("valid input rejected: got %v", err)
+	}
+	if err := check(s, op, Case{Input: in}, nil); err != nil {
+		t.Errorf("invalid input rejected: got %v", err)
+	}
+	var query string
+	s.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			query = c.QueryString()
+			return next(c)
+		}
+	})
+	if err := check(s, op, Case{Input: in, Raw: map[string]string{"query limit": "abc"}}, nil); err != nil || query != "code=1&limit=abc" {
+		t.Errorf("raw query: got %q %v", query, err)
+	}
+}
@@ -0,0 +1,262 @@
+// Package resttest calls operations of rest.Service or rest.Mux in-process for tests.
+//
+// Input is encoded into path, query, header, cookie and body by its struct tags, the same way
+// as by package client, and served through the full bind, validate and interact pipeline.
+// Bodies of rest.Patch and rest.FilePart inputs are sent with WithPatch and WithFile.
+package resttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/fourcels/rest"
+	"github.com/fourcels/rest/client"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler is implemented by *rest.Service and *rest.Mux.
+type Handler interface {
+	http.Handler
+	BaseURL() string
+	Operations() []rest.Operation
+}
+
+// Response of operation.
+type Response[O any] struct {
+	StatusCode int
+	Header     http.Header
+	Cookies    []*http.Cookie
+	// Output is decoded from successful response, headers and cookies of output are set as well.
+	Output O
+	// Err is decoded from error response, it is nil for successful response.
+	Err *rest.ErrResponse
+}
+
+type requestOption func(r *http.Request) error
+
+// WithHeader sets request header, e.g. Authorization.
+func WithHeader(name, value string) requestOption {
+	return func(r *http.Request) error {
+		r.Header.Set(name, value)
+		return nil
+	}
+}
+
+// WithCookie adds request cookie.
+func WithCookie(cookie *http.Cookie) requestOption {
+	return func(r *http.Request) error {
+		r.AddCookie(cookie)
+		return nil
+	}
+}
+
+// WithContext sets context of request.
+func WithContext(ctx context.Context) requestOption {
+	return func(r *http.Request) error {
+		*r = *r.WithContext(ctx)
+		return nil
+	}
+}
+
+// WithBody replaces body encoded from input with body of contentType, path, query, header and
+// cookie parameters of input are still sent. It is the escape hatch for bodies input can not
+// express, see WithPatch and WithFile for rest.Patch and rest.FilePart.
+func WithBody(contentType string, body io.Reader) requestOption {
+	return func(r *http.Request) error {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		setBody(r, contentType, data)
+		return nil
+	}
+}
+
+// WithPatch sends patch as body of rest.Patch input, []rest.PatchOperation as JSON Patch and
+// other values as JSON Merge Patch.
+func WithPatch(patch any) requestOption {
+	return func(r *http.Request) error {
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		mediaType := rest.MIMEApplicationMergePatchJSON
+		if _, ok := patch.([]rest.PatchOperation); ok {
+			mediaType = rest.MIMEApplicationJSONPatchJSON
+		}
+		setBody(r, mediaType, data)
+		return nil
+	}
+}
+
+// WithFile sends file as `multipart/form-data` part name of rest.FilePart input, it follows
+// `formData` fields of input and files of previous WithFile options.
+func WithFile(name string, file *client.File) requestOption {
+	return func(r *http.Request) error {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		if err := copyParts(w, r); err != nil {
+			return err
+		}
+		part, err := w.CreateFormFile(name, file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		setBody(r, w.FormDataContentType(), buf.Bytes())
+		return nil
+	}
+}
+
+// copyParts copies parts of `multipart/form-data` body of r into w.
+func copyParts(w *multipart.Writer, r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	if mediaType != echo.MIMEMultipartForm {
+		return fmt.Errorf("resttest: file can not be added to %s body of input", mediaType)
+	}
+	// Request is read directly, Request.MultipartReader would mark it as read for server.
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		dst, err := w.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, part); err != nil {
+			return err
+		}
+	}
+}
+
+func setBody(r *http.Request, contentType string, body []byte) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.ContentLength = int64(len(body))
+	r.Header.Set(echo.HeaderContentType, contentType)
+}
+
+// Call calls operation registered for method and pattern, e.g. `/users/{id}`, with input in.
+// Error is returned if operation is not registered or request can not be encoded, error
+// responses are returned in Response.Err.
+func Call[O any](h Handler, method, pattern string, in any, ops ...requestOption) (*Response[O], error) {
+	if !registered(h, method, pattern) {
+		return nil, fmt.Errorf("resttest: operation %s %s is not registered", method, pattern)
+	}
+	req, err := client.NewRequest(context.Background(), method, "http://example.com"+h.BaseURL()+pattern, in)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if err := op(req); err != nil {
+			return nil, err
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+	resp := &Response[O]{StatusCode: res.StatusCode, Header: res.Header, Cookies: res.Cookies()}
+	if res.StatusCode >= http.StatusBadRequest {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		resp.Err = &rest.ErrResponse{}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, resp.Err); err != nil {
+				return nil, fmt.Errorf("resttest: decode error response: %w", err)
+			}
+		}
+		return resp, nil
+	}
+	if err := client.DecodeResponse(res, &resp.Output); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// MustCall is Call that fails the test on error.
+func MustCall[O any](t testing.TB, h Handler, method, pattern string, in any, ops ...requestOption) *Response[O] {
+	t.Helper()
+	res, err := Call[O](h, method, pattern, in, ops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func registered(h Handler, method, pattern string) bool {
+	for _, op := range h.Operations() {
+		if op.Method == method && op.Path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// validated holds services with enabled response validation.
+var validated sync.Map
+
+// Contract returns Handler of s that fails t on responses not matching documented responses of
+// operation, e.g. undocumented status, header or body not matching schema. Violations of requests
+// served by the Handler are reported to t when the test ends, so a Service can be shared by
+// parallel tests. Service.ValidateResponses is enabled once per Service.
+func Contract(t testing.TB, s *rest.Service) Handler {
+	if _, ok := validated.LoadOrStore(s, true); !ok {
+		s.ValidateResponses()
+	}
+	h := &contractHandler{Service: s}
+	h.report = func(c echo.Context, err *rest.ContractError) error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.violations = append(h.violations, err)
+		return nil
+	}
+	t.Cleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for _, err := range h.violations {
+			t.Error(err)
+		}
+	})
+	return h
+}
+
+// contractHandler reports violations of its requests to one test.
+type contractHandler struct {
+	*rest.Service
+	report     func(c echo.Context, err *rest.ContractError) error
+	mu         sync.Mutex
+	violations []error
+}
+
+// ServeHTTP serves r with violations reported to the test of contractHandler.
+func (h *contractHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Service.ServeHTTP(w, r.WithContext(rest.WithContractReport(r.Context(), h.report)))
+}
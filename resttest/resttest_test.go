@@ -0,0 +1,147 @@
+package resttest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fourcels/rest"
+	"github.com/fourcels/rest/client"
+	"github.com/labstack/echo/v4"
+)
+
+type user struct {
+	Name string `json:"name" minLength:"3" required:"true"`
+	Age  int    `json:"age,omitempty"`
+}
+
+func newService() *rest.Service {
+	s := rest.NewService("/api")
+	s.GET("/users", rest.NewHandler(func(c echo.Context, in struct {
+		Page  int              `query:"page" minimum:"0"`
+		Admin bool             `query:"admin"`
+		Name  rest.Opt[string] `query:"name"`
+	}, out *[]string) error {
+		*out = []string{c.QueryString()}
+		return nil
+	}))
+	s.PATCH("/users/{id}", rest.NewHandler(func(c echo.Context, in struct {
+		ID    int `path:"id"`
+		Patch rest.Patch[user]
+	}, out *user) error {
+		*out = user{Name: "alice"}
+		return in.Patch.Apply(out)
+	}))
+	s.POST("/videos", rest.NewHandler(func(c echo.Context, in struct {
+		Title string        `formData:"title" required:"true"`
+		Video rest.FilePart `formData:"video" required:"true"`
+	}, out *string) error {
+		content, err := io.ReadAll(in.Video)
+		*out = in.Title + ": " + in.Video.FileName() + " " + string(content)
+		return err
+	}))
+	return s
+}
+
+func TestCallParameters(t *testing.T) {
+	s := newService()
+	res := MustCall[[]string](t, s, http.MethodGet, "/users", struct {
+		Page  int              `query:"page"`
+		Admin bool             `query:"admin"`
+		Name  rest.Opt[string] `query:"name"`
+	}{Name: rest.NullOpt[string]()})
+	if res.Err != nil || len(res.Output) != 1 || res.Output[0] != "admin=false&name=&page=0" {
+		t.Errorf("got %v %v, want zero and null parameters sent", res.Output, res.Err)
+	}
+}
+
+func TestCallPatch(t *testing.T) {
+	s := newService()
+	in := struct {
+		ID int `path:"id"`
+	}{ID: 1}
+	res := MustCall[user](t, s, http.MethodPatch, "/users/{id}", in, WithPatch(map[string]any{"age": 3}))
+	if res.Err != nil || res.Output != (user{Name: "alice", Age: 3}) {
+		t.Errorf("merge patch: got %+v %v", res.Output, res.Err)
+	}
+	res = MustCall[user](t, s, http.MethodPatch, "/users/{id}", in,
+		WithPatch([]rest.PatchOperation{{Op: "replace", Path: "/name", Value: "bob"}}))
+	if res.Err != nil || res.Output != (user{Name: "bob"}) {
+		t.Errorf("JSON patch: got %+v %v", res.Output, res.Err)
+	}
+	res = MustCall[user](t, s, http.MethodPatch, "/users/{id}", in,
+		WithBody(rest.MIMEApplicationMergePatchJSON, strings.NewReader(`{"name":"x"}`)))
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid patch: got %d, want 400", res.StatusCode)
+	}
+}
+
+func TestCallFile(t *testing.T) {
+	s := newService()
+	in := struct {
+		Title string        `formData:"title"`
+		Video rest.FilePart `formData:"video"`
+	}{Title: "cats"}
+	res := MustCall[string](t, s, http.MethodPost, "/videos", in,
+		WithFile("video", &client.File{Name: "cats.mp4", Content: strings.NewReader("meow")}))
+	if res.Err != nil || res.Output != "cats: cats.mp4 meow" {
+		t.Errorf("got %q %v", res.Output, res.Err)
+	}
+	res = MustCall[string](t, s, http.MethodPost, "/videos", in)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing file: got %d, want 400", res.StatusCode)
+	}
+}
+
+// reporter records errors of Contract instead of failing the test.
+type reporter struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *reporter) Error(args ...any) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *reporter) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func TestContract(t *testing.T) {
+	s := newService()
+	s.GET("/teapot", rest.NewHandler(func(c echo.Context, in struct{}, out *string) error {
+		return rest.HTTPCodeAsError(http.StatusTeapot)
+	}))
+
+	var wg sync.WaitGroup
+	reporters := make([]*reporter, 8)
+	for i := range reporters {
+		r := &reporter{TB: t}
+		reporters[i] = r
+		h := Contract(r, s)
+		pattern := "/users"
+		if i%2 == 1 {
+			pattern = "/teapot"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Call[any](h, http.MethodGet, pattern, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, r := range reporters {
+		for _, f := range r.cleanups {
+			f()
+		}
+		if want := i % 2; len(r.errors) != want {
+			t.Errorf("test %d got violations %v, want %d", i, r.errors, want)
+		}
+	}
+}
@@ -1,15 +1,15 @@
 package rest
 
 import (
-	"bytes"
 	_ "embed"
-	"html/template"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/swaggest/openapi-go/openapi3"
 )
 
 //go:embed swagger.tmpl
@@ -22,12 +22,49 @@ const (
 	SchemeBasic  = Scheme("basic")
 )
 
+// Router is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+	Any(path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) []*echo.Route
+	Group(prefix string, middleware ...echo.MiddlewareFunc) *echo.Group
+}
+
 type Service struct {
 	*echo.Echo
+	*document
 	baseUrl   string
-	group     *Group
-	reflector *openapi3.Reflector
-	OpenAPI   *openapi3.Spec
+	ops       []option
+	binder    *CustomBinder
+	validator *CustomValidator
+	router    Router
+	// scoped is set when Service is mounted on an existing app, errors are rendered by routes then.
+	scoped bool
+	group  *Group
+
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	onStart         []Hook
+	onShutdown      []shutdownHook
+	draining        atomic.Bool
+
+	healthChecks  []healthCheck
+	healthTimeout time.Duration
+	// unlogged holds route paths excluded from request logging.
+	unlogged map[string]bool
+	// instruments wrap every operation, see Instrument.
+	instruments []Instrumentation
+
+	logger    *slog.Logger
+	logBodies bool
+	// logPrincipal identifies principal in access log, see WithPrincipalLogging.
+	logPrincipal func(principal any) string
+	contract     atomic.Pointer[contract]
+	mock         *mocker
+
+	versioning *Versioning
+	// versions are documents of versions, from the oldest one.
+	versions        []*document
+	versionedRoutes map[string]*versionedRoute
 }
 
 func customHTTPErrorHandler(err error, c echo.Context) {
@@ -44,35 +81,117 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 	}
 }
 
-func NewService(baseUrl ...string) *Service {
+func newService(cfg *serviceConfig) *Service {
 	s := &Service{}
 
-	s.reflector = &openapi3.Reflector{}
-	s.OpenAPI = &openapi3.Spec{Openapi: "3.0.3"}
+	s.baseUrl = cfg.baseUrl
+	s.shutdownTimeout = cfg.shutdownTimeout
+	s.drainDelay = cfg.drainDelay
+	s.healthTimeout = cfg.healthTimeout
+	s.unlogged = map[string]bool{}
+	s.document = newDocument(s.baseUrl)
+	s.logBodies = cfg.logBodies
+	s.logPrincipal = cfg.logPrincipal
+	if cfg.logger != nil {
+		s.logger = cfg.logger
+		s.document.logger = cfg.logger
+	}
+	if cfg.info != nil {
+		s.OpenAPI.Info = *cfg.info
+	}
+	if cfg.servers != nil {
+		s.OpenAPI.Servers = cfg.servers
+	}
+	for _, setup := range cfg.reflector {
+		setup(s.reflector)
+	}
+	if cfg.versioning != nil && len(cfg.versions) > 0 {
+		s.versioning = cfg.versioning
+		s.versionedRoutes = map[string]*versionedRoute{}
+		for _, version := range cfg.versions {
+			d := newDocument("")
+			d.logger = s.document.logger
+			d.version = version
+			d.versions = cfg.versions
+			for _, setup := range cfg.reflector {
+				setup(d.reflector)
+			}
+			s.versions = append(s.versions, d)
+		}
+	}
+	if cfg.mock {
+		s.mock = &mocker{spec: s.OpenAPI}
+	}
+	s.binder = &CustomBinder{}
+	s.validator = &CustomValidator{}
+
+	return s
+}
+
+func NewService(baseUrl ...string) *Service {
+	var ops []serviceOption
 	if len(baseUrl) > 0 {
-		s.baseUrl = baseUrl[0]
-		s.OpenAPI.WithServers(openapi3.Server{
-			URL: s.baseUrl,
-		})
+		ops = append(ops, WithBaseURL(baseUrl[0]))
 	}
+	return New(ops...)
+}
+
+// New creates Service configured with options, e.g. WithBaseURL, WithMiddleware or WithJSONSerializer.
+func New(ops ...serviceOption) *Service {
+	cfg := newServiceConfig(ops)
+	s := newService(cfg)
 
-	s.reflector.Spec = s.OpenAPI
 	e := echo.New()
-	e.HideBanner = true
-	e.Binder = &CustomBinder{}
-	e.Validator = &CustomValidator{}
+	e.HideBanner = cfg.hideBanner
+	e.HidePort = cfg.hidePort
+	if cfg.serializer != nil {
+		e.JSONSerializer = cfg.serializer
+	}
+	e.Binder = s.binder
+	e.Validator = s.validator
 	e.HTTPErrorHandler = customHTTPErrorHandler
 
 	// Root level middleware
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	if cfg.customMiddleware {
+		e.Use(cfg.middleware...)
+	} else if s.logger != nil {
+		e.Use(s.logRequests)
+		e.Use(middleware.Recover())
+	} else {
+		e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{Skipper: s.skipLogging}))
+		e.Use(middleware.Recover())
+	}
 
 	s.Echo = e
+	s.router = e
 	s.group = s.Group("")
 
 	return s
 }
 
+// Mount attaches Service to an existing *echo.Echo or *echo.Group. Binding, validation and
+// error rendering are scoped to the routes of Service, Binder, Validator, HTTPErrorHandler
+// and middleware of the app are left untouched. Echo is nil when mounted on *echo.Group.
+//
+// Options that configure echo instance, e.g. WithMiddleware, are ignored.
+func Mount(r Router, ops ...serviceOption) *Service {
+	s := newService(newServiceConfig(ops))
+
+	if e, ok := r.(*echo.Echo); ok {
+		s.Echo = e
+	}
+	s.router = r
+	s.scoped = true
+	s.group = s.Group("")
+
+	return s
+}
+
+// BaseURL returns path prefix of all routes.
+func (s *Service) BaseURL() string {
+	return s.baseUrl
+}
+
 func (s *Service) GET(pattern string, h Interactor, middleware ...echo.MiddlewareFunc) *echo.Route {
 	return s.group.GET(pattern, h, middleware...)
 }
@@ -91,72 +210,35 @@ func (s *Service) DELETE(pattern string, h Interactor, middleware ...echo.Middle
 
 func (s *Service) Group(prefix string, ops ...option) *Group {
 	group := &Group{}
-	group.Group = s.Echo.Group(s.baseUrl + parenthesesToColon(prefix))
-	group.reflector = s.reflector
+	group.Group = s.router.Group(s.baseUrl + parenthesesToColon(prefix))
+	group.service = s
 	group.prefix = prefix
 	group.ops = ops
 	return group
 }
 
+// Docs serves spec and Swagger UI under pattern, with WithVersions every version is served
+// under pattern and version, e.g. `/docs/v2`, and listed by docs page of pattern.
 func (s *Service) Docs(pattern string, config ...map[string]any) {
 	pattern = strings.TrimRight(pattern, "/")
-	s.Echo.GET(s.baseUrl+pattern+"/openapi.json", func(c echo.Context) error {
-		schema, err := s.OpenAPI.MarshalJSON()
-		if err != nil {
-			return err
-		}
-		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
-		return c.String(http.StatusOK, string(schema))
-	})
-	s.Echo.Any(s.baseUrl+pattern+"*", func(c echo.Context) error {
-		t := template.Must(template.New("swagger").Parse(swagger))
-		var html bytes.Buffer
+	if len(s.versions) > 0 {
 		var setting map[string]any
 		if len(config) > 0 {
 			setting = config[0]
 		}
-		t.Execute(&html, map[string]any{
-			"AssetBase":   "https://unpkg.com/swagger-ui-dist",
-			"SwaggerJson": s.baseUrl + pattern + "/openapi.json",
-			"Setting":     setting,
-		})
-		return c.HTML(http.StatusOK, html.String())
-	})
-}
-
-func (s *Service) WithSecurity(key string, securityScheme *openapi3.SecurityScheme) {
-	s.OpenAPI.ComponentsEns().SecuritySchemesEns().WithMapOfSecuritySchemeOrRefValuesItem(key,
-		openapi3.SecuritySchemeOrRef{
-			SecurityScheme: securityScheme,
-		})
-}
-
-func (s *Service) WithHttpBearerSecurity(key string) {
-	s.WithHttpSecurity(key, SchemeBearer)
-}
-func (s *Service) WithHttpBasicSecurity(key string) {
-	s.WithHttpSecurity(key, SchemeBasic)
-}
-func (s *Service) WithHttpSecurity(key string, scheme Scheme) {
-	s.WithSecurity(key, &openapi3.SecurityScheme{
-		HTTPSecurityScheme: &openapi3.HTTPSecurityScheme{
-			Scheme: string(scheme),
-		},
-	})
-}
-func (s *Service) WithAPIKeySecurity(key, name string, in openapi3.APIKeySecuritySchemeIn) {
-	s.WithSecurity(key, &openapi3.SecurityScheme{
-		APIKeySecurityScheme: &openapi3.APIKeySecurityScheme{
-			In:   in,
-			Name: name,
-		},
-	})
-}
-
-func (s *Service) WithTags(val ...string) {
-	tags := make([]openapi3.Tag, 0)
-	for _, v := range val {
-		tags = append(tags, openapi3.Tag{Name: v})
+		config = []map[string]any{s.versionDocs(pattern, setting)}
 	}
-	s.OpenAPI.WithTags(tags...)
+	s.router.Add(http.MethodGet, s.baseUrl+pattern+"/openapi.json", echo.WrapHandler(http.HandlerFunc(s.specHandler)))
+	s.router.Any(s.baseUrl+pattern+"*", echo.WrapHandler(s.uiHandler(s.baseUrl+pattern+"/openapi.json", config...)))
+}
+
+// WithPrincipal sets extractor of authenticated principal bound to `auth` tagged input fields.
+func (s *Service) WithPrincipal(extractor func(c echo.Context) (any, error)) {
+	s.binder.Principal = extractor
+}
+
+// WithOptions applies operation options to every Interactor registered afterwards,
+// e.g. rest.WithMaxBodySize or rest.WithHandlerTimeout.
+func (s *Service) WithOptions(ops ...option) {
+	s.ops = append(s.ops, ops...)
 }
@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// serviceConfig holds settings of New and Mount.
+type serviceConfig struct {
+	baseUrl          string
+	middleware       []echo.MiddlewareFunc
+	customMiddleware bool
+	serializer       echo.JSONSerializer
+	hideBanner       bool
+	hidePort         bool
+	reflector        []func(r *openapi3.Reflector)
+	info             *openapi3.Info
+	servers          []openapi3.Server
+	shutdownTimeout  time.Duration
+	drainDelay       time.Duration
+	healthTimeout    time.Duration
+	logger           *slog.Logger
+	logBodies        bool
+	logPrincipal     func(principal any) string
+	mock             bool
+	versioning       *Versioning
+	versions         []string
+}
+
+type serviceOption func(cfg *serviceConfig)
+
+func newServiceConfig(ops []serviceOption) *serviceConfig {
+	cfg := &serviceConfig{hideBanner: true, shutdownTimeout: 30 * time.Second, healthTimeout: 5 * time.Second}
+	for _, op := range ops {
+		op(cfg)
+	}
+	return cfg
+}
+
+// WithBaseURL sets path prefix of all routes, it is also the server URL of spec.
+func WithBaseURL(baseUrl string) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.baseUrl = baseUrl
+	}
+}
+
+// WithMiddleware replaces default Logger and Recover root level middleware,
+// no middleware is installed if called without arguments.
+func WithMiddleware(middleware ...echo.MiddlewareFunc) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.middleware = append(cfg.middleware, middleware...)
+		cfg.customMiddleware = true
+	}
+}
+
+// WithJSONSerializer sets JSON encoder and decoder of requests and responses.
+func WithJSONSerializer(serializer echo.JSONSerializer) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.serializer = serializer
+	}
+}
+
+// WithHideBanner controls startup banner of echo, it is hidden by default.
+func WithHideBanner(hide bool) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.hideBanner = hide
+	}
+}
+
+// WithHidePort controls startup message with listening address.
+func WithHidePort(hide bool) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.hidePort = hide
+	}
+}
+
+// WithReflector customizes OpenAPI reflector, e.g. to add type mappings.
+func WithReflector(setup func(r *openapi3.Reflector)) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.reflector = append(cfg.reflector, setup)
+	}
+}
+
+// WithReflectOptions adds default JSON schema reflection options, e.g. jsonschema.InlineRefs,
+// jsonschema.InterceptDefName or jsonschema.InterceptSchema.
+func WithReflectOptions(options ...func(rc *jsonschema.ReflectContext)) serviceOption {
+	return WithReflector(func(r *openapi3.Reflector) {
+		r.DefaultOptions = append(r.DefaultOptions, options...)
+	})
+}
+
+// WithInfo sets spec info.
+func WithInfo(info openapi3.Info) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.info = &info
+	}
+}
+
+// WithServers sets spec servers instead of the one derived from base URL.
+func WithServers(servers ...openapi3.Server) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.servers = servers
+	}
+}
+
+// WithShutdownTimeout limits the time Service.Run waits for in-flight requests and shutdown hooks, 30s by default.
+func WithShutdownTimeout(d time.Duration) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.shutdownTimeout = d
+	}
+}
+
+// WithDrainDelay makes Service.Run keep serving for d after readiness flips to failing,
+// so that load balancers stop sending traffic before connections are refused.
+func WithDrainDelay(d time.Duration) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.drainDelay = d
+	}
+}
+
+// WithHealthTimeout limits the time of a single health check, 5s by default.
+func WithHealthTimeout(d time.Duration) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.healthTimeout = d
+	}
+}
+
+// WithLogger replaces text request logging of echo with structured access log of logger,
+// operation registration errors are logged with it as well. Access log has operation, route,
+// status, latency and request ID of requests, see WithPrincipalLogging.
+func WithLogger(logger *slog.Logger) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.logger = logger
+	}
+}
+
+// WithBodyLogging adds input and output of operations to access log, see WithLogger.
+// Fields tagged `sensitive` are redacted and fields tagged `log:"-"`, `auth` or `ctx` are omitted.
+func WithBodyLogging() serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.logBodies = true
+	}
+}
+
+// WithPrincipalLogging adds principal of requests to access log as identifier returned by
+// identify, e.g. subject of a token, principal is not logged otherwise.
+func WithPrincipalLogging(identify func(principal any) string) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.logPrincipal = identify
+	}
+}
+
+// WithMock makes Service answer with examples of responses instead of calling Interactors.
+// Requests are bound and validated, status and named example are selected with Prefer header,
+// e.g. `Prefer: code=404, example=missing`. Examples come from WithResponseExample, `example`
+// tags or are synthesized from schema.
+func WithMock() serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.mock = true
+	}
+}
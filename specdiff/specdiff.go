@@ -0,0 +1,485 @@
+// Package specdiff reports breaking and non-breaking changes between two OpenAPI specs,
+// e.g. a committed baseline and spec of the current Service.
+package specdiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+// Change of an operation.
+type Change struct {
+	// Operation is method and path, e.g. `GET /users/{id}`.
+	Operation string
+	Message   string
+}
+
+// String implements fmt.Stringer.
+func (c Change) String() string {
+	return c.Operation + ": " + c.Message
+}
+
+// Report of changes, breaking ones break existing clients.
+type Report struct {
+	Breaking    []Change
+	NonBreaking []Change
+}
+
+// String lists changes of report.
+func (r Report) String() string {
+	var b strings.Builder
+	for _, group := range []struct {
+		title   string
+		changes []Change
+	}{{"Breaking changes", r.Breaking}, {"Non-breaking changes", r.NonBreaking}} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", group.title)
+		for _, c := range group.changes {
+			fmt.Fprintf(&b, "  %s\n", c)
+		}
+	}
+	return b.String()
+}
+
+// MajorVersionChanged reports whether major part of info version differs, e.g. `1.4.0` and `2.0.0`.
+func MajorVersionChanged(base, current *openapi3.Spec) bool {
+	major := func(v string) string {
+		major, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), ".")
+		return major
+	}
+	return major(base.Info.Version) != major(current.Info.Version)
+}
+
+// Compare reports changes of current spec against base spec.
+func Compare(base, current *openapi3.Spec) Report {
+	d := &differ{base: base, current: current}
+	baseOps, currentOps := operations(base), operations(current)
+	for _, key := range sortedKeys(baseOps) {
+		cur, ok := currentOps[key]
+		if !ok {
+			d.breaking(key, "operation is removed")
+			continue
+		}
+		d.operation(key, baseOps[key], cur)
+	}
+	for _, key := range sortedKeys(currentOps) {
+		if _, ok := baseOps[key]; !ok {
+			d.nonBreaking(key, "operation is added")
+		}
+	}
+	return d.report
+}
+
+type differ struct {
+	base, current *openapi3.Spec
+	report        Report
+}
+
+func (d *differ) breaking(op, format string, args ...any) {
+	d.report.Breaking = append(d.report.Breaking, Change{op, fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) nonBreaking(op, format string, args ...any) {
+	d.report.NonBreaking = append(d.report.NonBreaking, Change{op, fmt.Sprintf(format, args...)})
+}
+
+// operation is operation with parameters of its path item.
+type operation struct {
+	openapi3.Operation
+	params []openapi3.Parameter
+}
+
+func operations(spec *openapi3.Spec) map[string]operation {
+	res := map[string]operation{}
+	for path, item := range spec.Paths.MapOfPathItemValues {
+		for method, op := range item.MapOfOperationValues {
+			o := operation{Operation: op}
+			for _, p := range append(append([]openapi3.ParameterOrRef{}, item.Parameters...), op.Parameters...) {
+				if p.Parameter != nil {
+					o.params = append(o.params, *p.Parameter)
+				}
+			}
+			res[strings.ToUpper(method)+" "+path] = o
+		}
+	}
+	return res
+}
+
+func (d *differ) operation(key string, base, current operation) {
+	d.params(key, base.params, current.params)
+	d.requestBody(key, base.RequestBody, current.RequestBody)
+	d.responses(key, base.Responses, current.Responses)
+	d.security(key, base.Security, current.Security)
+	if !isTrue(base.Deprecated) && isTrue(current.Deprecated) {
+		d.nonBreaking(key, "operation is deprecated")
+	}
+}
+
+func (d *differ) params(key string, base, current []openapi3.Parameter) {
+	index := func(params []openapi3.Parameter) map[string]openapi3.Parameter {
+		res := map[string]openapi3.Parameter{}
+		for _, p := range params {
+			res[string(p.In)+" parameter "+p.Name] = p
+		}
+		return res
+	}
+	baseParams, currentParams := index(base), index(current)
+	for _, name := range sortedKeys(currentParams) {
+		cur := currentParams[name]
+		old, ok := baseParams[name]
+		switch {
+		case !ok && isTrue(cur.Required):
+			d.breaking(key, "required %s is added", name)
+		case !ok:
+			d.nonBreaking(key, "%s is added", name)
+		case !isTrue(old.Required) && isTrue(cur.Required):
+			d.breaking(key, "%s is required", name)
+		case isTrue(old.Required) && !isTrue(cur.Required):
+			d.nonBreaking(key, "%s is optional", name)
+		}
+		if ok {
+			d.schema(key, name, old.Schema, cur.Schema, true, map[string]bool{})
+		}
+	}
+	for _, name := range sortedKeys(baseParams) {
+		if _, ok := currentParams[name]; !ok {
+			d.nonBreaking(key, "%s is removed", name)
+		}
+	}
+}
+
+func (d *differ) requestBody(key string, base, current *openapi3.RequestBodyOrRef) {
+	var old, cur *openapi3.RequestBody
+	if base != nil {
+		old = base.RequestBody
+	}
+	if current != nil {
+		cur = current.RequestBody
+	}
+	switch {
+	case old == nil && cur == nil:
+		return
+	case old == nil:
+		if isTrue(cur.Required) {
+			d.breaking(key, "required request body is added")
+		} else {
+			d.nonBreaking(key, "request body is added")
+		}
+		for _, mediaType := range sortedKeys(cur.Content) {
+			d.schema(key, "request body", nil, cur.Content[mediaType].Schema, true, map[string]bool{})
+		}
+		return
+	case cur == nil:
+		d.nonBreaking(key, "request body is removed")
+		return
+	}
+	if !isTrue(old.Required) && isTrue(cur.Required) {
+		d.breaking(key, "request body is required")
+	}
+	for _, mediaType := range sortedKeys(old.Content) {
+		c, ok := cur.Content[mediaType]
+		if !ok {
+			d.breaking(key, "request body %s is removed", mediaType)
+			continue
+		}
+		d.schema(key, "request body", old.Content[mediaType].Schema, c.Schema, true, map[string]bool{})
+	}
+	for _, mediaType := range sortedKeys(cur.Content) {
+		if _, ok := old.Content[mediaType]; !ok {
+			d.nonBreaking(key, "request body %s is added", mediaType)
+		}
+	}
+}
+
+func (d *differ) responses(key string, base, current openapi3.Responses) {
+	for _, status := range sortedKeys(base.MapOfResponseOrRefValues) {
+		old := base.MapOfResponseOrRefValues[status].Response
+		res, ok := current.MapOfResponseOrRefValues[status]
+		if !ok || res.Response == nil {
+			if strings.HasPrefix(status, "2") {
+				d.breaking(key, "response %s is removed", status)
+			} else {
+				d.nonBreaking(key, "response %s is removed", status)
+			}
+			continue
+		}
+		if old == nil {
+			continue
+		}
+		for _, name := range sortedKeys(old.Headers) {
+			if _, ok := res.Response.Headers[name]; !ok {
+				d.breaking(key, "response %s header %s is removed", status, name)
+			}
+		}
+		for _, mediaType := range sortedKeys(old.Content) {
+			c, ok := res.Response.Content[mediaType]
+			if !ok {
+				d.breaking(key, "response %s %s is removed", status, mediaType)
+				continue
+			}
+			d.schema(key, "response "+status, old.Content[mediaType].Schema, c.Schema, false, map[string]bool{})
+		}
+	}
+	for _, status := range sortedKeys(current.MapOfResponseOrRefValues) {
+		if _, ok := base.MapOfResponseOrRefValues[status]; !ok {
+			d.nonBreaking(key, "response %s is added", status)
+		}
+	}
+}
+
+func (d *differ) security(key string, base, current []map[string][]string) {
+	schemes := func(reqs []map[string][]string) map[string]bool {
+		res := map[string]bool{}
+		for _, req := range reqs {
+			for name, scopes := range req {
+				res[strings.TrimSpace(name+" "+strings.Join(scopes, " "))] = true
+			}
+		}
+		return res
+	}
+	old, cur := schemes(base), schemes(current)
+	for _, name := range sortedKeys(cur) {
+		if !old[name] {
+			d.breaking(key, "security %s is added", name)
+		}
+	}
+	for _, name := range sortedKeys(old) {
+		if !cur[name] {
+			if len(cur) == 0 {
+				d.nonBreaking(key, "security %s is removed", name)
+			} else {
+				d.breaking(key, "security %s is replaced", name)
+			}
+		}
+	}
+}
+
+// schema compares schemas at location, request schemas break clients when they narrow,
+// response schemas when they widen or lose fields. seen holds compared references.
+func (d *differ) schema(key, loc string, base, current *openapi3.SchemaOrRef, request bool, seen map[string]bool) {
+	if base != nil && base.SchemaReference != nil && current != nil && current.SchemaReference != nil {
+		ref := base.SchemaReference.Ref + " " + current.SchemaReference.Ref
+		if seen[ref] {
+			return
+		}
+		seen[ref] = true
+	}
+	old, cur := resolve(d.base, base), resolve(d.current, current)
+	if old == nil {
+		if cur != nil && request {
+			for _, name := range cur.Required {
+				d.breaking(key, "%s field %s is required", loc, name)
+			}
+		}
+		return
+	}
+	if cur == nil {
+		return
+	}
+	if t1, t2 := schemaType(old), schemaType(cur); t1 != t2 {
+		d.breaking(key, "%s type is changed from %s to %s", loc, t1, t2)
+		return
+	}
+	if f1, f2 := str(old.Format), str(cur.Format); f1 != f2 {
+		d.breaking(key, "%s format is changed from %q to %q", loc, f1, f2)
+	}
+	if isTrue(old.Nullable) != isTrue(cur.Nullable) {
+		if request == isTrue(old.Nullable) {
+			d.breaking(key, "%s nullable is changed to %t", loc, isTrue(cur.Nullable))
+		} else {
+			d.nonBreaking(key, "%s nullable is changed to %t", loc, isTrue(cur.Nullable))
+		}
+	}
+	d.enum(key, loc, old.Enum, cur.Enum, request)
+	d.constraints(key, loc, old, cur, request)
+
+	oldRequired, curRequired := set(old.Required), set(cur.Required)
+	for _, name := range sortedKeys(old.Properties) {
+		field := loc + "." + name
+		prop, ok := cur.Properties[name]
+		if !ok {
+			if request {
+				d.nonBreaking(key, "%s field is removed", field)
+			} else {
+				d.breaking(key, "%s field is removed", field)
+			}
+			continue
+		}
+		switch {
+		case request && !oldRequired[name] && curRequired[name]:
+			d.breaking(key, "%s field is required", field)
+		case !request && oldRequired[name] && !curRequired[name]:
+			d.breaking(key, "%s field is optional", field)
+		}
+		oldProp := old.Properties[name]
+		d.schema(key, field, &oldProp, &prop, request, seen)
+	}
+	for _, name := range sortedKeys(cur.Properties) {
+		if _, ok := old.Properties[name]; ok {
+			continue
+		}
+		if request && curRequired[name] {
+			d.breaking(key, "required %s.%s field is added", loc, name)
+		} else {
+			d.nonBreaking(key, "%s.%s field is added", loc, name)
+		}
+	}
+	if old.Items != nil && cur.Items != nil {
+		d.schema(key, loc+"[]", old.Items, cur.Items, request, seen)
+	}
+	if old.AdditionalProperties != nil && cur.AdditionalProperties != nil {
+		d.schema(key, loc+"{}", old.AdditionalProperties.SchemaOrRef, cur.AdditionalProperties.SchemaOrRef, request, seen)
+	}
+}
+
+func (d *differ) enum(key, loc string, base, current []any, request bool) {
+	if len(base) == 0 && len(current) == 0 {
+		return
+	}
+	old, cur := values(base), values(current)
+	if len(base) == 0 {
+		if request {
+			d.breaking(key, "%s enum is added", loc)
+		}
+		return
+	}
+	if len(current) == 0 {
+		if !request {
+			d.breaking(key, "%s enum is removed", loc)
+		}
+		return
+	}
+	for _, v := range sortedKeys(old) {
+		if !cur[v] {
+			if request {
+				d.breaking(key, "%s enum value %s is removed", loc, v)
+			} else {
+				d.nonBreaking(key, "%s enum value %s is removed", loc, v)
+			}
+		}
+	}
+	for _, v := range sortedKeys(cur) {
+		if !old[v] {
+			if request {
+				d.nonBreaking(key, "%s enum value %s is added", loc, v)
+			} else {
+				d.breaking(key, "%s enum value %s is added", loc, v)
+			}
+		}
+	}
+}
+
+// constraints compares validation keywords, narrowing breaks requests and widening breaks responses.
+func (d *differ) constraints(key, loc string, old, cur *openapi3.Schema, request bool) {
+	limit(d, key, loc, "maximum", old.Maximum, cur.Maximum, true, request)
+	limit(d, key, loc, "minimum", old.Minimum, cur.Minimum, false, request)
+	limit(d, key, loc, "maxLength", old.MaxLength, cur.MaxLength, true, request)
+	limit(d, key, loc, "minLength", old.MinLength, cur.MinLength, false, request)
+	limit(d, key, loc, "maxItems", old.MaxItems, cur.MaxItems, true, request)
+	limit(d, key, loc, "minItems", old.MinItems, cur.MinItems, false, request)
+
+	p1, p2 := str(old.Pattern), str(cur.Pattern)
+	switch {
+	case p1 == p2:
+	case p1 == "":
+		d.narrowed(key, request, true, "%s pattern %q is added", loc, p2)
+	case p2 == "":
+		d.narrowed(key, request, false, "%s pattern %q is removed", loc, p1)
+	default:
+		// Patterns can not be compared, a changed one may reject values of either side.
+		d.breaking(key, "%s pattern is changed from %q to %q", loc, p1, p2)
+	}
+}
+
+// limit compares bound of name, upper bounds narrow when they decrease, lower ones when they increase.
+func limit[T int64 | float64](d *differ, key, loc, name string, base, current *T, upper, request bool) {
+	switch {
+	case base == nil && current == nil:
+	case base == nil:
+		d.narrowed(key, request, true, "%s %s %v is added", loc, name, *current)
+	case current == nil:
+		d.narrowed(key, request, false, "%s %s %v is removed", loc, name, *base)
+	case *base != *current:
+		d.narrowed(key, request, (*current < *base) == upper, "%s %s is changed from %v to %v", loc, name, *base, *current)
+	}
+}
+
+// narrowed reports change of accepted values, narrowing breaks requests and widening responses.
+func (d *differ) narrowed(key string, request, narrowed bool, format string, args ...any) {
+	if request == narrowed {
+		d.breaking(key, format, args...)
+	} else {
+		d.nonBreaking(key, format, args...)
+	}
+}
+
+// resolve returns schema of s, following reference to components of spec.
+func resolve(spec *openapi3.Spec, s *openapi3.SchemaOrRef) *openapi3.Schema {
+	for i := 0; s != nil && i < 10; i++ {
+		if s.SchemaReference == nil {
+			return s.Schema
+		}
+		if spec.Components == nil || spec.Components.Schemas == nil {
+			return nil
+		}
+		ref, ok := spec.Components.Schemas.MapOfSchemaOrRefValues[strings.TrimPrefix(s.SchemaReference.Ref, componentsPrefix)]
+		if !ok {
+			return nil
+		}
+		s = &ref
+	}
+	return nil
+}
+
+func schemaType(s *openapi3.Schema) string {
+	if s.Type != nil {
+		return string(*s.Type)
+	}
+	if len(s.Properties) > 0 {
+		return string(openapi3.SchemaTypeObject)
+	}
+	return "any"
+}
+
+func values(enum []any) map[string]bool {
+	res := map[string]bool{}
+	for _, v := range enum {
+		res[fmt.Sprintf("%q", fmt.Sprint(v))] = true
+	}
+	return res
+}
+
+func set(items []string) map[string]bool {
+	res := map[string]bool{}
+	for _, item := range items {
+		res[item] = true
+	}
+	return res
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
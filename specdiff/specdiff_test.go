@@ -0,0 +1,171 @@
+package specdiff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// spec returns spec with paths and component schemas in JSON.
+func spec(t *testing.T, paths, schemas string) *openapi3.Spec {
+	t.Helper()
+	if schemas == "" {
+		schemas = "{}"
+	}
+	s := &openapi3.Spec{}
+	data := `{"openapi":"3.0.3","info":{"title":"test","version":"1.0.0"},"paths":` + paths +
+		`,"components":{"schemas":` + schemas + `}}`
+	if err := s.UnmarshalJSON([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// getUsers returns paths with `GET /users` operation of parameters and 200 response schema.
+func getUsers(params, response string) string {
+	if params == "" {
+		params = "[]"
+	}
+	return `{"/users":{"get":{"parameters":` + params + `,"responses":{"200":{"description":"OK",` +
+		`"content":{"application/json":{"schema":` + response + `}}}}}}}`
+}
+
+// postUsers returns paths with `POST /users` operation of request body schema.
+func postUsers(body string) string {
+	return `{"/users":{"post":{"requestBody":{"required":true,"content":{"application/json":{"schema":` + body +
+		`}}},"responses":{"204":{"description":"No Content"}}}}}`
+}
+
+const user = `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"}},"required":["id","name"]}`
+
+func TestCompare(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		base, current        string
+		baseSchemas, schemas string
+		breaking             []string
+		nonBreaking          []string
+	}{
+		{
+			name:        "operation",
+			base:        `{"/users":{"get":{"responses":{"204":{"description":"OK"}}},"delete":{"responses":{"204":{"description":"OK"}}}}}`,
+			current:     `{"/users":{"get":{"responses":{"204":{"description":"OK"}}}},"/items":{"get":{"responses":{"204":{"description":"OK"}}}}}`,
+			breaking:    []string{"DELETE /users: operation is removed"},
+			nonBreaking: []string{"GET /items: operation is added"},
+		},
+		{
+			name:        "parameters",
+			base:        getUsers(`[{"name":"page","in":"query","schema":{"type":"integer"}},{"name":"sort","in":"query","schema":{"type":"string"}}]`, user),
+			current:     getUsers(`[{"name":"page","in":"query","required":true,"schema":{"type":"integer"}},{"name":"q","in":"query","required":true,"schema":{"type":"string"}},{"name":"X-Trace","in":"header","schema":{"type":"string"}}]`, user),
+			breaking:    []string{"GET /users: query parameter page is required", "GET /users: required query parameter q is added"},
+			nonBreaking: []string{"GET /users: header parameter X-Trace is added", "GET /users: query parameter sort is removed"},
+		},
+		{
+			name:     "parameter type",
+			base:     getUsers(`[{"name":"page","in":"query","schema":{"type":"integer"}}]`, user),
+			current:  getUsers(`[{"name":"page","in":"query","schema":{"type":"string"}}]`, user),
+			breaking: []string{"GET /users: query parameter page type is changed from integer to string"},
+		},
+		{
+			name:        "request enum",
+			base:        getUsers(`[{"name":"sort","in":"query","schema":{"type":"string","enum":["asc","desc"]}}]`, user),
+			current:     getUsers(`[{"name":"sort","in":"query","schema":{"type":"string","enum":["asc","name"]}}]`, user),
+			breaking:    []string{`GET /users: query parameter sort enum value "desc" is removed`},
+			nonBreaking: []string{`GET /users: query parameter sort enum value "name" is added`},
+		},
+		{
+			name:        "request fields",
+			base:        postUsers(`{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}}}`),
+			current:     postUsers(`{"type":"object","properties":{"name":{"type":"string"},"email":{"type":"string"},"note":{"type":"string"}},"required":["name","email"]}`),
+			breaking:    []string{"POST /users: request body.name field is required", "POST /users: required request body.email field is added"},
+			nonBreaking: []string{"POST /users: request body.age field is removed", "POST /users: request body.note field is added"},
+		},
+		{
+			name:        "referenced request fields",
+			base:        postUsers(`{"$ref":"#/components/schemas/User"}`),
+			current:     postUsers(`{"$ref":"#/components/schemas/User"}`),
+			baseSchemas: `{"User":{"type":"object","properties":{"name":{"type":"string"}}}}`,
+			schemas:     `{"User":{"type":"object","properties":{"name":{"type":"string"},"role":{"type":"string"}},"required":["role"]}}`,
+			breaking:    []string{"POST /users: required request body.role field is added"},
+		},
+		{
+			name: "request constraints narrowed",
+			base: postUsers(`{"type":"object","properties":{"age":{"type":"integer","minimum":0,"maximum":150},` +
+				`"name":{"type":"string","minLength":1,"maxLength":100},"code":{"type":"string"}}}`),
+			current: postUsers(`{"type":"object","properties":{"age":{"type":"integer","minimum":18,"maximum":120},` +
+				`"name":{"type":"string","minLength":3,"maxLength":50},"code":{"type":"string","pattern":"^[A-Z]+$"}}}`),
+			breaking: []string{
+				`POST /users: request body.age maximum is changed from 150 to 120`,
+				`POST /users: request body.age minimum is changed from 0 to 18`,
+				`POST /users: request body.code pattern "^[A-Z]+$" is added`,
+				`POST /users: request body.name maxLength is changed from 100 to 50`,
+				`POST /users: request body.name minLength is changed from 1 to 3`,
+			},
+		},
+		{
+			name: "request constraints widened",
+			base: postUsers(`{"type":"object","properties":{"age":{"type":"integer","maximum":120},` +
+				`"code":{"type":"string","pattern":"^[A-Z]+$"},"tags":{"type":"array","items":{"type":"string"},"maxItems":3}}}`),
+			current: postUsers(`{"type":"object","properties":{"age":{"type":"integer","maximum":150},` +
+				`"code":{"type":"string"},"tags":{"type":"array","items":{"type":"string"}}}}`),
+			nonBreaking: []string{
+				`POST /users: request body.age maximum is changed from 120 to 150`,
+				`POST /users: request body.code pattern "^[A-Z]+$" is removed`,
+				`POST /users: request body.tags maxItems 3 is removed`,
+			},
+		},
+		{
+			name:     "request pattern changed",
+			base:     getUsers(`[{"name":"code","in":"query","schema":{"type":"string","pattern":"^[a-z]+$"}}]`, user),
+			current:  getUsers(`[{"name":"code","in":"query","schema":{"type":"string","pattern":"^[0-9]+$"}}]`, user),
+			breaking: []string{`GET /users: query parameter code pattern is changed from "^[a-z]+$" to "^[0-9]+$"`},
+		},
+		{
+			name:        "response fields",
+			base:        getUsers("", user),
+			current:     getUsers("", `{"type":"object","properties":{"id":{"type":"string"},"email":{"type":"string"}},"required":["id"]}`),
+			breaking:    []string{"GET /users: response 200.id type is changed from integer to string", "GET /users: response 200.name field is removed"},
+			nonBreaking: []string{"GET /users: response 200.email field is added"},
+		},
+		{
+			name:        "response enum and constraints",
+			base:        getUsers("", `{"type":"object","properties":{"status":{"type":"string","enum":["active","blocked"]},"score":{"type":"integer","maximum":10}}}`),
+			current:     getUsers("", `{"type":"object","properties":{"status":{"type":"string","enum":["active","deleted"]},"score":{"type":"integer","maximum":100}}}`),
+			breaking:    []string{"GET /users: response 200.score maximum is changed from 10 to 100", `GET /users: response 200.status enum value "deleted" is added`},
+			nonBreaking: []string{`GET /users: response 200.status enum value "blocked" is removed`},
+		},
+		{
+			name:        "responses",
+			base:        `{"/users":{"get":{"responses":{"200":{"description":"OK"},"404":{"description":"Not Found"}}}}}`,
+			current:     `{"/users":{"get":{"responses":{"201":{"description":"Created"}}}}}`,
+			breaking:    []string{"GET /users: response 200 is removed"},
+			nonBreaking: []string{"GET /users: response 404 is removed", "GET /users: response 201 is added"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Compare(spec(t, tt.base, tt.baseSchemas), spec(t, tt.current, tt.schemas))
+			if got := messages(report.Breaking); !reflect.DeepEqual(got, tt.breaking) {
+				t.Errorf("breaking:\n got %q\nwant %q", got, tt.breaking)
+			}
+			if got := messages(report.NonBreaking); !reflect.DeepEqual(got, tt.nonBreaking) {
+				t.Errorf("non-breaking:\n got %q\nwant %q", got, tt.nonBreaking)
+			}
+		})
+	}
+}
+
+func TestCompareIdentical(t *testing.T) {
+	s := spec(t, getUsers(`[{"name":"page","in":"query","schema":{"type":"integer","minimum":0}}]`, user), "")
+	if report := Compare(s, s); len(report.Breaking) > 0 || len(report.NonBreaking) > 0 {
+		t.Errorf("got changes:\n%s", report)
+	}
+}
+
+func messages(changes []Change) []string {
+	var res []string
+	for _, c := range changes {
+		res = append(res, c.String())
+	}
+	return res
+}
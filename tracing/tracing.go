@@ -0,0 +1,99 @@
+// Package tracing traces operations of rest.Service with OpenTelemetry.
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fourcels/rest"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/fourcels/rest/tracing"
+
+// Enable traces operations of s with provider, the global one is used if omitted.
+// A server span named after operation, e.g. `GET /api/users/{id}`, has child spans of bind,
+// validate, interact and encode phases. Incoming trace context is extracted with the global
+// propagator, see otel.SetTextMapPropagator.
+func Enable(s *rest.Service, provider ...trace.TracerProvider) {
+	tp := otel.GetTracerProvider()
+	if len(provider) > 0 {
+		tp = provider[0]
+	}
+	tracer := tp.Tracer(tracerName)
+	s.Instrument(func(method, route string) echo.MiddlewareFunc {
+		return serve(tracer, method, s.BaseURL()+route)
+	})
+}
+
+// serve opens server span of route and observes phases of request with child spans.
+func serve(tracer trace.Tracer, method, route string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := tracer.Start(ctx, method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+					semconv.URLScheme(c.Scheme()),
+					semconv.ServerAddress(req.Host),
+					semconv.ClientAddress(c.RealIP()),
+					semconv.UserAgentOriginal(req.UserAgent()),
+				),
+			)
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+			rest.ObservePhases(c, func(c echo.Context, phase string) func(err error) {
+				req := c.Request()
+				ctx, span := tracer.Start(req.Context(), phase)
+				c.SetRequest(req.WithContext(ctx))
+				return func(err error) {
+					c.SetRequest(c.Request().WithContext(req.Context()))
+					if err != nil {
+						recordError(span, err)
+					}
+					span.End()
+				}
+			})
+
+			err := next(c)
+			status := c.Response().Status
+			if err != nil && !c.Response().Committed {
+				status, _ = rest.Err(err)
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
+
+// recordError records err of phase to span, fields of validation errors are recorded as events.
+// Span status is error if err is responded with 5xx, wrapped errors are classified like by rest.Err.
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	if status, _ := rest.Err(err); status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	var withFields rest.ErrWithFields
+	if errors.As(err, &withFields) {
+		for field, message := range withFields.Fields() {
+			span.AddEvent("validation error", trace.WithAttributes(
+				attribute.String("field", field),
+				attribute.String("message", fmt.Sprint(message)),
+			))
+		}
+	}
+}
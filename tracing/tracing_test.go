@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fourcels/rest"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEnable(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator()) })
+
+	s := rest.New(rest.WithBaseURL("/api"))
+	s.GET("/users/{id}", rest.NewHandler(func(c echo.Context, in struct {
+		ID int `path:"id" minimum:"1"`
+	}, out *string) error {
+		if !trace.SpanFromContext(c.Request().Context()).SpanContext().IsValid() {
+			t.Error("interact has no span in request context")
+		}
+		*out = "user"
+		return nil
+	}))
+	sr := tracetest.NewSpanRecorder()
+	Enable(s, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := sr.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("got %d spans, want 5", len(spans))
+	}
+	server := spans[4]
+	if server.Name() != "GET /api/users/{id}" || server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span %q of kind %v", server.Name(), server.SpanKind())
+	}
+	if got := attr(server.Attributes(), semconv.HTTPRouteKey); got != "/api/users/{id}" {
+		t.Errorf("http.route = %q, want /api/users/{id}", got)
+	}
+	if got := attr(server.Attributes(), semconv.HTTPResponseStatusCodeKey); got != "200" {
+		t.Errorf("status = %q, want 200", got)
+	}
+	if got := server.Parent().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" || !server.Parent().IsRemote() {
+		t.Errorf("parent trace %s is not propagated", got)
+	}
+	for i, name := range []string{"bind", "validate", "interact", "encode"} {
+		if spans[i].Name() != name || spans[i].Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("span %d is %q of parent %s, want %q of server span", i, spans[i].Name(), spans[i].Parent().SpanID(), name)
+		}
+	}
+
+	sr = tracetest.NewSpanRecorder()
+	s = rest.New(rest.WithBaseURL("/api"))
+	s.GET("/users/{id}", rest.NewHandler(func(c echo.Context, in struct {
+		ID int `path:"id" minimum:"1"`
+	}, out *string) error {
+		return nil
+	}))
+	Enable(s, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/0", nil))
+	spans = sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	validate := spans[1]
+	var fields []string
+	for _, event := range validate.Events() {
+		if event.Name == "validation error" {
+			fields = append(fields, attr(event.Attributes, "field"))
+		}
+	}
+	if validate.Name() != "validate" || len(fields) != 1 || fields[0] != "path:id" {
+		t.Errorf("span %q has validation errors of %v, want path:id", validate.Name(), fields)
+	}
+	if got := attr(spans[2].Attributes(), semconv.HTTPResponseStatusCodeKey); got != "400" {
+		t.Errorf("status = %q, want 400", got)
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	for _, tt := range []struct {
+		err    error
+		status string
+		code   codes.Code
+	}{
+		{fmt.Errorf("load user: %w", echo.NewHTTPError(http.StatusNotFound)), "404", codes.Unset},
+		{errors.New("connection refused"), "500", codes.Error},
+	} {
+		sr := tracetest.NewSpanRecorder()
+		s := rest.New(rest.WithMiddleware())
+		s.GET("/users", rest.NewHandler(func(c echo.Context, in struct{}, out *string) error {
+			return tt.err
+		}))
+		Enable(s, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		spans := sr.Ended()
+		if len(spans) != 4 {
+			t.Fatalf("got %d spans, want 4", len(spans))
+		}
+		interact, server := spans[2], spans[3]
+		if got := attr(server.Attributes(), semconv.HTTPResponseStatusCodeKey); got != tt.status {
+			t.Errorf("%v: status = %q, want %s", tt.err, got, tt.status)
+		}
+		if interact.Status().Code != tt.code || server.Status().Code != tt.code {
+			t.Errorf("%v: got span status %v and %v, want %v", tt.err, interact.Status().Code,
+				server.Status().Code, tt.code)
+		}
+		if len(interact.Events()) == 0 || interact.Events()[0].Name != "exception" {
+			t.Errorf("%v: error is not recorded", tt.err)
+		}
+	}
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) string {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
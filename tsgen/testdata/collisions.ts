@@ -0,0 +1,240 @@
+// Code generated by tsgen. DO NOT EDIT.
+
+/** Error response of service. */
+export interface ErrResponse {
+  /** Status text. */
+  status?: string;
+  /** Application-specific error code. */
+  code?: number;
+  /** Error message. */
+  error?: string;
+  /** Application context. */
+  context?: Record<string, unknown>;
+}
+
+/** ApiError is thrown for error responses. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: ErrResponse,
+  ) {
+    super(body.error || body.status || "HTTP " + status);
+    this.name = "ApiError";
+  }
+}
+
+export type Client2 = "web" | "mobile";
+
+export interface CreateUserInput {
+  name: string;
+}
+
+export interface GetUserOutput {
+  user?: User;
+}
+
+export interface User {
+  client?: Client2;
+  id: number;
+  name: string;
+}
+
+/** Input of POST /requests. */
+export interface RequestInput {}
+
+/** Output of POST /requests. */
+export type RequestOutput = void;
+
+/** Input of GET /users. */
+export interface GetUsersInput {
+  page?: number | null;
+  "X-Token": string;
+}
+
+/** Output of GET /users. */
+export type GetUsersOutput = User[];
+
+/** Input of POST /users. */
+export interface CreateUser2Input {
+  body: CreateUserInput;
+}
+
+/** Output of POST /users. */
+export type CreateUser2Output = User;
+
+/** Input of GET /users/{id}. */
+export interface GetUser2Input {
+  id: number;
+}
+
+/** Output of GET /users/{id}. */
+export type GetUser2Output = GetUserOutput;
+
+/** Input of PATCH /users/{id}. */
+export interface GetUser3Input {
+  id: number;
+  body?: Partial<User>;
+}
+
+/** Output of PATCH /users/{id}. */
+export type GetUser3Output = void;
+
+/** Input of PUT /users/{id}/avatar. */
+export interface PutUsersIdAvatarInput {
+  id: number;
+  body?: {
+    avatar?: Blob;
+  };
+}
+
+/** Output of PUT /users/{id}/avatar. */
+export type PutUsersIdAvatarOutput = void;
+
+export interface ClientOptions {
+  /** Base URL of service, server URL of spec by default. */
+  baseUrl?: string;
+  fetch?: typeof fetch;
+  /** Headers sent with every request, e.g. Authorization. */
+  headers?: Record<string, string>;
+}
+
+type Params = Record<string, unknown>;
+
+type Encoding = "json" | "multipart" | "form" | "merge-patch" | "none";
+
+export class Client {
+  private readonly baseUrl: string;
+  private readonly fetch: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseUrl = (options.baseUrl ?? "/api").replace(/\/$/, "");
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+
+  /**
+   * POST /requests
+   */
+  request_(input: RequestInput = {}, init?: RequestInit): Promise<RequestOutput> {
+    return this.request("POST", "/requests", {}, {}, {}, undefined, "none", init);
+  }
+
+  /**
+   * GET /users
+   */
+  getUsers(input: GetUsersInput, init?: RequestInit): Promise<GetUsersOutput> {
+    return this.request("GET", "/users", {}, { page: input.page }, { "X-Token": input["X-Token"] }, undefined, "none", init);
+  }
+
+  /**
+   * POST /users
+   */
+  createUser(input: CreateUser2Input, init?: RequestInit): Promise<CreateUser2Output> {
+    return this.request("POST", "/users", {}, {}, {}, input.body, "json", init);
+  }
+
+  /**
+   * GET /users/{id}
+   */
+  getUser(input: GetUser2Input, init?: RequestInit): Promise<GetUser2Output> {
+    return this.request("GET", "/users/{id}", { id: input.id }, {}, {}, undefined, "none", init);
+  }
+
+  /**
+   * PATCH /users/{id}
+   */
+  getUser_(input: GetUser3Input, init?: RequestInit): Promise<GetUser3Output> {
+    return this.request("PATCH", "/users/{id}", { id: input.id }, {}, {}, input.body, "merge-patch", init);
+  }
+
+  /**
+   * PUT /users/{id}/avatar
+   */
+  putUsersIdAvatar(input: PutUsersIdAvatarInput, init?: RequestInit): Promise<PutUsersIdAvatarOutput> {
+    return this.request("PUT", "/users/{id}/avatar", { id: input.id }, {}, {}, input.body, "multipart", init);
+  }
+
+  private async request<T>(
+    method: string,
+    pattern: string,
+    path: Params,
+    query: Params,
+    header: Params,
+    body: unknown,
+    encoding: Encoding,
+    init?: RequestInit,
+  ): Promise<T> {
+    let url = this.baseUrl + pattern.replace(/\{(\w+)\}/g, (_, name: string) => encodeURIComponent(String(path[name])));
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(query)) {
+      for (const item of Array.isArray(value) ? value : [value]) {
+        if (item !== undefined) {
+          search.append(name, item === null ? "" : String(item));
+        }
+      }
+    }
+    if (search.toString()) {
+      url += "?" + search.toString();
+    }
+    const headers = new Headers(init?.headers);
+    for (const [name, value] of Object.entries(this.headers)) {
+      if (!headers.has(name)) {
+        headers.set(name, value);
+      }
+    }
+    for (const [name, value] of Object.entries(header)) {
+      if (value !== undefined && value !== null) {
+        headers.set(name, String(value));
+      }
+    }
+    let payload: BodyInit | undefined;
+    if (body !== undefined) {
+      switch (encoding) {
+        case "json":
+        case "merge-patch":
+          headers.set("Content-Type", encoding === "json" ? "application/json" : "application/merge-patch+json");
+          payload = JSON.stringify(body);
+          break;
+        case "form": {
+          const form = new URLSearchParams();
+          for (const [name, value] of Object.entries(body as Params)) {
+            for (const item of Array.isArray(value) ? value : [value]) {
+              if (item !== undefined && item !== null) {
+                form.append(name, String(item));
+              }
+            }
+          }
+          payload = form;
+          break;
+        }
+        case "multipart": {
+          const form = new FormData();
+          for (const [name, value] of Object.entries(body as Params)) {
+            for (const item of Array.isArray(value) ? value : [value]) {
+              if (item instanceof Blob) {
+                form.append(name, item);
+              } else if (item !== undefined && item !== null) {
+                form.append(name, String(item));
+              }
+            }
+          }
+          payload = form;
+          break;
+        }
+      }
+    }
+    const res = await this.fetch(url, { ...init, method, headers, body: payload });
+    const text = await res.text();
+    if (!res.ok) {
+      let err: ErrResponse = {};
+      try {
+        err = JSON.parse(text) as ErrResponse;
+      } catch {
+        err = { status: res.statusText };
+      }
+      throw new ApiError(res.status, err);
+    }
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+}
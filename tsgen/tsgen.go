@@ -0,0 +1,633 @@
+// Package tsgen generates TypeScript types and a fetch based client from OpenAPI spec of Service.
+//
+// Output is deterministic: schemas, properties and operations are sorted, so it can be committed
+// and diffed.
+package tsgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+var (
+	identPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	methodOrder  = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+)
+
+// WriteFile generates TypeScript client of spec into filename, see Generate.
+func WriteFile(filename string, spec *openapi3.Spec) error {
+	src, err := Generate(spec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, 0o644)
+}
+
+// Generate returns TypeScript source with interfaces of spec schemas, input and output types of
+// operations and Client with a method per operation. Error responses are thrown as ApiError
+// carrying ErrResponse. Methods are named after operationId, or method and path if it is not set.
+func Generate(spec *openapi3.Spec) ([]byte, error) {
+	g := &generator{
+		spec:  spec,
+		names: map[string]string{},
+		used:  map[string]bool{"ErrResponse": true, "ApiError": true, "Client": true, "ClientOptions": true, "Params": true, "Encoding": true},
+	}
+	w := &strings.Builder{}
+	w.WriteString("// Code generated by tsgen. DO NOT EDIT.\n")
+	w.WriteString(prelude)
+
+	var schemas map[string]openapi3.SchemaOrRef
+	if spec.Components != nil && spec.Components.Schemas != nil {
+		schemas = spec.Components.Schemas.MapOfSchemaOrRefValues
+	}
+	names := sortedKeys(schemas)
+	for _, name := range names {
+		g.names[name] = g.unique(pascal(name))
+	}
+	for _, name := range names {
+		schema := schemas[name]
+		g.declare(w, g.names[name], &schema)
+	}
+
+	ops := g.operations()
+	for _, op := range ops {
+		g.declareOperation(w, op)
+	}
+
+	baseUrl := ""
+	if len(spec.Servers) > 0 {
+		baseUrl = spec.Servers[0].URL
+	}
+	fmt.Fprintf(w, clientHead, quote(baseUrl))
+	for _, op := range ops {
+		g.clientMethod(w, op)
+	}
+	w.WriteString(clientTail)
+	return []byte(w.String()), nil
+}
+
+type generator struct {
+	spec *openapi3.Spec
+	// names of component schemas in generated source.
+	names map[string]string
+	used  map[string]bool
+}
+
+type operation struct {
+	method   string
+	path     string
+	name     string
+	typeName string
+	spec     openapi3.Operation
+	params   []openapi3.Parameter
+	body     *openapi3.SchemaOrRef
+	encoding string
+	required bool
+	output   *openapi3.SchemaOrRef
+}
+
+// operations returns operations of spec sorted by path and method.
+func (g *generator) operations() []*operation {
+	var res []*operation
+	// Members of Client are reserved.
+	methods := map[string]bool{"constructor": true, "request": true, "baseUrl": true, "fetch": true, "headers": true}
+	for _, path := range sortedKeys(g.spec.Paths.MapOfPathItemValues) {
+		item := g.spec.Paths.MapOfPathItemValues[path]
+		for _, method := range methodOrder {
+			spec, ok := item.MapOfOperationValues[method]
+			if !ok {
+				continue
+			}
+			op := &operation{method: strings.ToUpper(method), path: path, spec: spec}
+			base := method + " " + path
+			if spec.ID != nil && *spec.ID != "" {
+				base = *spec.ID
+			}
+			op.typeName = g.operationName(pascal(base))
+			op.name = camel(pascal(base))
+			for methods[op.name] {
+				op.name += "_"
+			}
+			methods[op.name] = true
+
+			for _, p := range append(append([]openapi3.ParameterOrRef{}, item.Parameters...), spec.Parameters...) {
+				// Cookies are sent by browser, they are not set by the client.
+				if p.Parameter != nil && p.Parameter.In != openapi3.ParameterInCookie {
+					op.params = append(op.params, *p.Parameter)
+				}
+			}
+			if spec.RequestBody != nil && spec.RequestBody.RequestBody != nil {
+				body := spec.RequestBody.RequestBody
+				op.required = body.Required != nil && *body.Required
+				for _, enc := range []struct{ mediaType, encoding string }{
+					{"application/json", "json"},
+					{"multipart/form-data", "multipart"},
+					{"application/x-www-form-urlencoded", "form"},
+					{"application/merge-patch+json", "merge-patch"},
+				} {
+					if media, ok := body.Content[enc.mediaType]; ok {
+						op.body, op.encoding = media.Schema, enc.encoding
+						break
+					}
+				}
+			}
+			op.output = successSchema(spec.Responses)
+			res = append(res, op)
+		}
+	}
+	return res
+}
+
+// successSchema returns JSON schema of the first 2xx response, nil if it has no body.
+func successSchema(responses openapi3.Responses) *openapi3.SchemaOrRef {
+	for _, code := range sortedKeys(responses.MapOfResponseOrRefValues) {
+		res := responses.MapOfResponseOrRefValues[code]
+		if !strings.HasPrefix(code, "2") || res.Response == nil {
+			continue
+		}
+		for _, mediaType := range sortedKeys(res.Response.Content) {
+			if strings.Contains(mediaType, "json") {
+				schema := res.Response.Content[mediaType].Schema
+				if schema == nil {
+					return &openapi3.SchemaOrRef{}
+				}
+				return schema
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (g *generator) declare(w *strings.Builder, name string, s *openapi3.SchemaOrRef) {
+	if s.Schema != nil {
+		doc(w, "", s.Schema)
+		if len(s.Schema.Properties) > 0 && s.Schema.AdditionalProperties == nil && len(s.Schema.AllOf) == 0 &&
+			(s.Schema.Nullable == nil || !*s.Schema.Nullable) {
+			fmt.Fprintf(w, "\nexport interface %s %s\n", name, g.object(s.Schema, ""))
+			return
+		}
+	}
+	fmt.Fprintf(w, "\nexport type %s = %s;\n", name, g.tsType(s, ""))
+}
+
+func (g *generator) declareOperation(w *strings.Builder, op *operation) {
+	fmt.Fprintf(w, "\n/** Input of %s %s. */\nexport interface %sInput {", op.method, op.path, op.typeName)
+	if len(op.params) == 0 && op.body == nil {
+		w.WriteString("}\n")
+	} else {
+		w.WriteString("\n")
+		g.inputFields(w, op)
+		w.WriteString("}\n")
+	}
+
+	output := "void"
+	if op.output != nil {
+		output = g.tsType(op.output, "")
+	}
+	fmt.Fprintf(w, "\n/** Output of %s %s. */\nexport type %sOutput = %s;\n", op.method, op.path, op.typeName, output)
+}
+
+func (g *generator) inputFields(w *strings.Builder, op *operation) {
+	for _, p := range op.params {
+		if p.Description != nil || (p.Schema != nil && p.Schema.Schema != nil) {
+			var s openapi3.Schema
+			if p.Schema != nil && p.Schema.Schema != nil {
+				s = *p.Schema.Schema
+			}
+			if p.Description != nil {
+				s.Description = p.Description
+			}
+			doc(w, "  ", &s)
+		}
+		optional := "?"
+		if p.Required != nil && *p.Required {
+			optional = ""
+		}
+		fmt.Fprintf(w, "  %s%s: %s;\n", property(p.Name), optional, g.tsType(p.Schema, "  "))
+	}
+	if op.body != nil {
+		optional := "?"
+		if op.required {
+			optional = ""
+		}
+		body := g.tsType(op.body, "  ")
+		if op.encoding == "merge-patch" {
+			body = "Partial<" + body + ">"
+		}
+		fmt.Fprintf(w, "  body%s: %s;\n", optional, body)
+	}
+}
+
+func (g *generator) clientMethod(w *strings.Builder, op *operation) {
+	var comment []string
+	if op.spec.Summary != nil && *op.spec.Summary != "" {
+		comment = append(comment, *op.spec.Summary)
+	}
+	comment = append(comment, op.method+" "+op.path)
+	if op.spec.Deprecated != nil && *op.spec.Deprecated {
+		comment = append(comment, "@deprecated")
+	}
+	fmt.Fprintf(w, "\n  /**\n")
+	for _, line := range comment {
+		fmt.Fprintf(w, "   * %s\n", line)
+	}
+	fmt.Fprintf(w, "   */\n")
+
+	params := map[openapi3.ParameterIn][]string{}
+	for _, p := range op.params {
+		params[p.In] = append(params[p.In], fmt.Sprintf("%s: input%s", property(p.Name), access(p.Name)))
+	}
+	obj := func(in openapi3.ParameterIn) string {
+		if len(params[in]) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(params[in], ", ") + " }"
+	}
+	body, encoding := "undefined", "none"
+	if op.body != nil {
+		body, encoding = "input.body", op.encoding
+	}
+	arg := "input: " + op.typeName + "Input"
+	if len(op.params) == 0 && (op.body == nil || !op.required) {
+		arg += " = {}"
+	}
+	fmt.Fprintf(w, "  %s(%s, init?: RequestInit): Promise<%sOutput> {\n", op.name, arg, op.typeName)
+	fmt.Fprintf(w, "    return this.request(%s, %s, %s, %s, %s, %s, %q, init);\n",
+		quote(op.method), quote(op.path), obj(openapi3.ParameterInPath), obj(openapi3.ParameterInQuery),
+		obj(openapi3.ParameterInHeader), body, encoding)
+	w.WriteString("  }\n")
+}
+
+// tsType returns TypeScript type of schema, nested object literals are indented by indent.
+func (g *generator) tsType(s *openapi3.SchemaOrRef, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.SchemaReference != nil {
+		name := strings.TrimPrefix(s.SchemaReference.Ref, componentsPrefix)
+		if res, ok := g.names[name]; ok {
+			return res
+		}
+		return "unknown"
+	}
+	if s.Schema == nil {
+		return "unknown"
+	}
+	schema := s.Schema
+	res := g.schemaType(schema, indent)
+	if schema.Nullable != nil && *schema.Nullable && res != "unknown" {
+		res = group(res) + " | null"
+	}
+	return res
+}
+
+func (g *generator) schemaType(schema *openapi3.Schema, indent string) string {
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			values = append(values, literal(v))
+		}
+		return strings.Join(values, " | ")
+	}
+	if len(schema.AllOf) > 0 {
+		return g.union(schema.AllOf, " & ", indent)
+	}
+	if len(schema.OneOf) > 0 {
+		return g.union(schema.OneOf, " | ", indent)
+	}
+	if len(schema.AnyOf) > 0 {
+		return g.union(schema.AnyOf, " | ", indent)
+	}
+	typ := ""
+	if schema.Type != nil {
+		typ = string(*schema.Type)
+	}
+	switch typ {
+	case "string":
+		if schema.Format != nil && *schema.Format == "binary" {
+			return "Blob"
+		}
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return group(g.tsType(schema.Items, indent)) + "[]"
+	case "object", "":
+		var parts []string
+		if len(schema.Properties) > 0 {
+			parts = append(parts, g.object(schema, indent))
+		}
+		if ap := schema.AdditionalProperties; ap != nil {
+			if ap.SchemaOrRef != nil {
+				parts = append(parts, "Record<string, "+g.tsType(ap.SchemaOrRef, indent)+">")
+			} else if ap.Bool == nil || *ap.Bool {
+				parts = append(parts, "Record<string, unknown>")
+			}
+		}
+		if len(parts) == 0 {
+			if typ == "object" {
+				return "Record<string, unknown>"
+			}
+			return "unknown"
+		}
+		return strings.Join(parts, " & ")
+	}
+	return "unknown"
+}
+
+func (g *generator) union(items []openapi3.SchemaOrRef, sep, indent string) string {
+	types := make([]string, 0, len(items))
+	for i := range items {
+		types = append(types, group(g.tsType(&items[i], indent)))
+	}
+	return strings.Join(types, sep)
+}
+
+// object returns object literal type of schema properties.
+func (g *generator) object(schema *openapi3.Schema, indent string) string {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	w := &strings.Builder{}
+	w.WriteString("{\n")
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		if prop.Schema != nil {
+			doc(w, indent+"  ", prop.Schema)
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(w, "%s  %s%s: %s;\n", indent, property(name), optional, g.tsType(&prop, indent+"  "))
+	}
+	w.WriteString(indent + "}")
+	return w.String()
+}
+
+// doc writes JSDoc of schema with description, default value and deprecation.
+func doc(w *strings.Builder, indent string, s *openapi3.Schema) {
+	var lines []string
+	if s.Description != nil && *s.Description != "" {
+		lines = append(lines, strings.Split(*s.Description, "\n")...)
+	}
+	if s.Format != nil && *s.Format != "" && *s.Format != "binary" {
+		lines = append(lines, "@format "+*s.Format)
+	}
+	if s.Default != nil {
+		lines = append(lines, "@default "+literal(*s.Default))
+	}
+	if s.Deprecated != nil && *s.Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	switch len(lines) {
+	case 0:
+	case 1:
+		fmt.Fprintf(w, "%s/** %s */\n", indent, escapeComment(lines[0]))
+	default:
+		fmt.Fprintf(w, "%s/**\n", indent)
+		for _, line := range lines {
+			fmt.Fprintf(w, "%s * %s\n", indent, escapeComment(line))
+		}
+		fmt.Fprintf(w, "%s */\n", indent)
+	}
+}
+
+func escapeComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "*\\/")
+}
+
+// group parenthesizes union and intersection types.
+func group(t string) string {
+	if strings.ContainsAny(t, "|&") && !strings.HasPrefix(t, "{") {
+		return "(" + t + ")"
+	}
+	return t
+}
+
+func literal(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "unknown"
+	}
+	return string(b)
+}
+
+func quote(s string) string {
+	return literal(s)
+}
+
+// property returns property name, quoted if it is not an identifier, e.g. `"X-Token"`.
+func property(name string) string {
+	if identPattern.MatchString(name) {
+		return name
+	}
+	return quote(name)
+}
+
+// access returns property accessor, e.g. `.id` or `["X-Token"]`.
+func access(name string) string {
+	if identPattern.MatchString(name) {
+		return "." + name
+	}
+	return "[" + quote(name) + "]"
+}
+
+func (g *generator) unique(name string) string {
+	res := name
+	for i := 2; g.used[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	g.used[res] = true
+	return res
+}
+
+// operationName returns name of operation whose Input and Output types do not collide with
+// component schemas and other operations.
+func (g *generator) operationName(name string) string {
+	res := name
+	for i := 2; g.used[res+"Input"] || g.used[res+"Output"]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	g.used[res+"Input"], g.used[res+"Output"] = true, true
+	return res
+}
+
+// pascal converts s, e.g. `get /users/{id}`, to PascalCase identifier.
+func pascal(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "Op" + b.String()
+	}
+	return b.String()
+}
+
+// camel converts PascalCase s to camelCase.
+func camel(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const prelude = `
+/** Error response of service. */
+export interface ErrResponse {
+  /** Status text. */
+  status?: string;
+  /** Application-specific error code. */
+  code?: number;
+  /** Error message. */
+  error?: string;
+  /** Application context. */
+  context?: Record<string, unknown>;
+}
+
+/** ApiError is thrown for error responses. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: ErrResponse,
+  ) {
+    super(body.error || body.status || "HTTP " + status);
+    this.name = "ApiError";
+  }
+}
+`
+
+const clientHead = `
+export interface ClientOptions {
+  /** Base URL of service, server URL of spec by default. */
+  baseUrl?: string;
+  fetch?: typeof fetch;
+  /** Headers sent with every request, e.g. Authorization. */
+  headers?: Record<string, string>;
+}
+
+type Params = Record<string, unknown>;
+
+type Encoding = "json" | "multipart" | "form" | "merge-patch" | "none";
+
+export class Client {
+  private readonly baseUrl: string;
+  private readonly fetch: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseUrl = (options.baseUrl ?? %s).replace(/\/$/, "");
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+`
+
+const clientTail = `
+  private async request<T>(
+    method: string,
+    pattern: string,
+    path: Params,
+    query: Params,
+    header: Params,
+    body: unknown,
+    encoding: Encoding,
+    init?: RequestInit,
+  ): Promise<T> {
+    let url = this.baseUrl + pattern.replace(/\{(\w+)\}/g, (_, name: string) => encodeURIComponent(String(path[name])));
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(query)) {
+      for (const item of Array.isArray(value) ? value : [value]) {
+        if (item !== undefined) {
+          search.append(name, item === null ? "" : String(item));
+        }
+      }
+    }
+    if (search.toString()) {
+      url += "?" + search.toString();
+    }
+    const headers = new Headers(init?.headers);
+    for (const [name, value] of Object.entries(this.headers)) {
+      if (!headers.has(name)) {
+        headers.set(name, value);
+      }
+    }
+    for (const [name, value] of Object.entries(header)) {
+      if (value !== undefined && value !== null) {
+        headers.set(name, String(value));
+      }
+    }
+    let payload: BodyInit | undefined;
+    if (body !== undefined) {
+      switch (encoding) {
+        case "json":
+        case "merge-patch":
+          headers.set("Content-Type", encoding === "json" ? "application/json" : "application/merge-patch+json");
+          payload = JSON.stringify(body);
+          break;
+        case "form": {
+          const form = new URLSearchParams();
+          for (const [name, value] of Object.entries(body as Params)) {
+            for (const item of Array.isArray(value) ? value : [value]) {
+              if (item !== undefined && item !== null) {
+                form.append(name, String(item));
+              }
+            }
+          }
+          payload = form;
+          break;
+        }
+        case "multipart": {
+          const form = new FormData();
+          for (const [name, value] of Object.entries(body as Params)) {
+            for (const item of Array.isArray(value) ? value : [value]) {
+              if (item instanceof Blob) {
+                form.append(name, item);
+              } else if (item !== undefined && item !== null) {
+                form.append(name, String(item));
+              }
+            }
+          }
+          payload = form;
+          break;
+        }
+      }
+    }
+    const res = await this.fetch(url, { ...init, method, headers, body: payload });
+    const text = await res.text();
+    if (!res.ok) {
+      let err: ErrResponse = {};
+      try {
+        err = JSON.parse(text) as ErrResponse;
+      } catch {
+        err = { status: res.statusText };
+      }
+      throw new ApiError(res.status, err);
+    }
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+}
+`
@@ -0,0 +1,68 @@
+package tsgen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var declaration = regexp.MustCompile(`(?m)^(?:export )?(?:interface|type|class) ([A-Za-z_$][A-Za-z0-9_$]*)`)
+
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile("testdata/collisions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := &openapi3.Spec{}
+	if err := spec.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := "testdata/collisions.ts"
+	if *update {
+		if err := os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("output differs from %s, run go test -update:\n%s", golden, src)
+	}
+
+	declared := map[string]bool{}
+	for _, m := range declaration.FindAllSubmatch(src, -1) {
+		if name := string(m[1]); declared[name] {
+			t.Errorf("%s is declared twice", name)
+		} else {
+			declared[name] = true
+		}
+	}
+
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		t.Skip("tsc is not installed, output is not compiled")
+	}
+	file := filepath.Join(t.TempDir(), "api.ts")
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(tsc, "--noEmit", "--strict", "--target", "es2020", "--lib", "es2020,dom", file).CombinedOutput()
+	if err != nil {
+		t.Errorf("tsc: %v\n%s", err, out)
+	}
+}
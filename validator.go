@@ -106,6 +106,13 @@ func structToMap(item any, tagName string) map[string]any {
 		}
 		tag := strings.Split(field.Tag.Get(tagName), ",")[0]
 		fieldVal := structField.Interface()
+		if opt, ok := fieldVal.(optional); ok {
+			value, present := opt.optValue()
+			if !present {
+				continue
+			}
+			fieldVal = value
+		}
 		if field.Anonymous && tag == "" {
 			res = mergeMaps(res, structToMap(fieldVal, tagName))
 			continue
@@ -0,0 +1,250 @@
+package rest
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+// HeaderAPIVersion selects API version of request with VersionByHeader, e.g. `API-Version: v2`.
+const HeaderAPIVersion = "API-Version"
+
+const (
+	versionInPath      = "path"
+	versionInHeader    = "header"
+	versionInMediaType = "mediaType"
+)
+
+// Versioning selects API version of request, see WithVersions.
+type Versioning struct {
+	in   string
+	name string
+}
+
+// VersionByPath selects version by path prefix following base URL, e.g. `/api/v2/users`.
+func VersionByPath() Versioning {
+	return Versioning{in: versionInPath}
+}
+
+// VersionByHeader selects version by request header, e.g. HeaderAPIVersion. Requests without
+// it are served by the latest version, the version is sent back in the same header.
+func VersionByHeader(name string) Versioning {
+	return Versioning{in: versionInHeader, name: name}
+}
+
+// VersionByMediaType selects version by parameter of Accept media type, e.g.
+// `Accept: application/json; version=v2`. Requests without it are served by the latest version.
+func VersionByMediaType(param string) Versioning {
+	return Versioning{in: versionInMediaType, name: param}
+}
+
+// WithVersions serves operations in versions, ordered from the oldest one. Every version has
+// its own spec and docs page, operations are placed into versions by WithVersionRange or
+// Service.Version, all versions by default.
+func WithVersions(by Versioning, versions ...string) serviceOption {
+	return func(cfg *serviceConfig) {
+		cfg.versioning = &by
+		cfg.versions = versions
+	}
+}
+
+// WithVersionRange places operation into versions from the one of from to the one of to,
+// both inclusive. Empty from is the oldest version and empty to is the latest one, registration
+// of operation with unknown version panics.
+func WithVersionRange(from, to string) option {
+	return operationOption(func(op *operation) {
+		op.versions = [2]string{from, to}
+	})
+}
+
+// includes reports whether operation is in version of document, documents without version
+// include every operation. Unknown version in range of operation panics.
+func (d *document) includes(op *operation) bool {
+	if d.version == "" {
+		return true
+	}
+	from, to := 0, len(d.versions)-1
+	if op.versions[0] != "" {
+		from = slices.Index(d.versions, op.versions[0])
+	}
+	if op.versions[1] != "" {
+		to = slices.Index(d.versions, op.versions[1])
+	}
+	if from < 0 || to < 0 {
+		panic(fmt.Sprintf("rest: %s %s: unknown version in range %s..%s", op.method, op.path, op.versions[0], op.versions[1]))
+	}
+	i := slices.Index(d.versions, d.version)
+	return from <= i && i <= to
+}
+
+// Version returns Group of operations served in version only.
+func (s *Service) Version(version string, ops ...option) *Group {
+	return s.Group("", append([]option{WithVersionRange(version, version)}, ops...)...)
+}
+
+// Spec returns spec of version, info, servers, tags and security schemes are taken from
+// Service. Spec of Service is returned for empty version, nil for unknown one.
+func (s *Service) Spec(version string) *openapi3.Spec {
+	if version == "" {
+		return s.OpenAPI
+	}
+	i := slices.IndexFunc(s.versions, func(d *document) bool { return d.version == version })
+	if i < 0 {
+		return nil
+	}
+	spec := *s.versions[i].OpenAPI
+	spec.Info = s.OpenAPI.Info
+	spec.Info.Version = version
+	spec.Tags = s.OpenAPI.Tags
+	spec.Servers = s.OpenAPI.Servers
+	if s.versioning.in == versionInPath {
+		spec.Servers = []openapi3.Server{{URL: s.baseUrl + "/" + version}}
+		for _, server := range s.OpenAPI.Servers {
+			if server.URL != s.baseUrl {
+				server.URL = strings.TrimRight(server.URL, "/") + "/" + version
+				spec.Servers = append(spec.Servers, server)
+			}
+		}
+	}
+	if s.OpenAPI.Components != nil && s.OpenAPI.Components.SecuritySchemes != nil {
+		components := openapi3.Components{}
+		if spec.Components != nil {
+			components = *spec.Components
+		}
+		components.SecuritySchemes = s.OpenAPI.Components.SecuritySchemes
+		spec.Components = &components
+	}
+	return &spec
+}
+
+// Operations returns registered Interactors in order of registration, operations of versions
+// follow unversioned ones. Paths include version prefix with VersionByPath.
+func (s *Service) Operations() []Operation {
+	res := s.document.Operations()
+	for _, d := range s.versions {
+		for _, op := range d.Operations() {
+			op.Version = d.version
+			if s.versioning.in == versionInPath {
+				op.Path = "/" + d.version + op.Path
+			}
+			res = append(res, op)
+		}
+	}
+	return res
+}
+
+// versionedRoute dispatches requests of a route to handlers of versions.
+type versionedRoute struct {
+	handlers map[string]echo.HandlerFunc
+}
+
+// addVersioned registers operation of Interactor h in versions of its range.
+func (s *Service) addVersioned(g *Group, method, pattern string, h Interactor, ops []option, middleware []echo.MiddlewareFunc) *echo.Route {
+	var route *echo.Route
+	for _, d := range s.versions {
+		op := d.addOperation(method, g.prefix+pattern, h, ops)
+		if op == nil {
+			continue
+		}
+		if s.versioning.in == versionInPath {
+			path := "/" + d.version + g.prefix + pattern
+			handler, mw := s.handler(op, method, path, middleware)
+			// Version precedes prefix of group in path, middleware of group is added here.
+			mw = append(append([]echo.MiddlewareFunc{}, g.middleware...), mw...)
+			route = s.router.Add(method, s.baseUrl+parenthesesToColon(path), handler, mw...)
+			continue
+		}
+		handler, mw := s.handler(op, method, g.prefix+pattern, middleware)
+		for i := len(mw) - 1; i >= 0; i-- {
+			handler = mw[i](handler)
+		}
+		key := method + " " + g.prefix + pattern
+		vr, ok := s.versionedRoutes[key]
+		if !ok {
+			vr = &versionedRoute{handlers: map[string]echo.HandlerFunc{}}
+			s.versionedRoutes[key] = vr
+			route = g.Add(method, parenthesesToColon(pattern), s.dispatch(vr))
+		}
+		vr.handlers[d.version] = handler
+	}
+	return route
+}
+
+// dispatch serves request with handler of its version.
+func (s *Service) dispatch(vr *versionedRoute) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		version, err := s.requestVersion(c.Request())
+		if err == nil {
+			handler, ok := vr.handlers[version]
+			if ok {
+				header := c.Response().Header()
+				header.Add(echo.HeaderVary, s.versioningHeader())
+				if s.versioning.in == versionInHeader {
+					header.Set(s.versioning.name, version)
+				}
+				return handler(c)
+			}
+			err = echo.NewHTTPError(http.StatusNotFound, "operation is not available in version "+version)
+		}
+		if s.scoped {
+			customHTTPErrorHandler(err, c)
+			return nil
+		}
+		return err
+	}
+}
+
+// versioningHeader returns request header selecting version.
+func (s *Service) versioningHeader() string {
+	if s.versioning.in == versionInHeader {
+		return s.versioning.name
+	}
+	return echo.HeaderAccept
+}
+
+// requestVersion returns version selected by request, the latest one if it is not set.
+func (s *Service) requestVersion(r *http.Request) (string, error) {
+	var version string
+	if s.versioning.in == versionInHeader {
+		version = r.Header.Get(s.versioning.name)
+	} else {
+		for _, accept := range strings.Split(r.Header.Get(echo.HeaderAccept), ",") {
+			if _, params, err := mime.ParseMediaType(accept); err == nil && params[s.versioning.name] != "" {
+				version = params[s.versioning.name]
+				break
+			}
+		}
+	}
+	if version == "" {
+		return s.versions[len(s.versions)-1].version, nil
+	}
+	if !slices.ContainsFunc(s.versions, func(d *document) bool { return d.version == version }) {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "unsupported version "+version)
+	}
+	return version, nil
+}
+
+// versionDocs serves spec and docs page of every version under pattern, docs page of pattern
+// lists all of them.
+func (s *Service) versionDocs(pattern string, config map[string]any) map[string]any {
+	var urls []map[string]string
+	for i := len(s.versions) - 1; i >= 0; i-- {
+		version := s.versions[i].version
+		specURL := s.baseUrl + pattern + "/" + version + "/openapi.json"
+		s.router.Add(http.MethodGet, specURL, echo.WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeSpec(w, s.Spec(version))
+		})))
+		s.router.Any(s.baseUrl+pattern+"/"+version+"*", echo.WrapHandler(s.uiHandler(specURL, config)))
+		urls = append(urls, map[string]string{"url": specURL, "name": version})
+	}
+	setting := map[string]any{"urls": urls}
+	for k, v := range config {
+		setting[k] = v
+	}
+	return setting
+}
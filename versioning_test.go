@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestVersionedGroupMiddleware(t *testing.T) {
+	auth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAuthorization) != "Bearer token" {
+				return echo.ErrUnauthorized
+			}
+			return next(c)
+		}
+	}
+	hello := NewHandler(func(c echo.Context, in struct{}, out *string) error {
+		*out = "hello"
+		return nil
+	})
+	for _, by := range []Versioning{VersionByPath(), VersionByHeader(HeaderAPIVersion)} {
+		t.Run(by.in, func(t *testing.T) {
+			s := New(WithBaseURL("/api"), WithVersions(by, "v1", "v2"))
+			admin := s.Group("/admin")
+			admin.Use(auth)
+			admin.GET("/hello", hello)
+
+			for _, version := range []string{"v1", "v2"} {
+				for authorization, want := range map[string]int{"": http.StatusUnauthorized, "Bearer token": http.StatusOK} {
+					path := "/api/admin/hello"
+					if by.in == versionInPath {
+						path = "/api/" + version + "/admin/hello"
+					}
+					req := httptest.NewRequest(http.MethodGet, path, nil)
+					req.Header.Set(HeaderAPIVersion, version)
+					if authorization != "" {
+						req.Header.Set(echo.HeaderAuthorization, authorization)
+					}
+					rec := httptest.NewRecorder()
+					s.ServeHTTP(rec, req)
+					if rec.Code != want {
+						t.Errorf("%s %q: got %d, want %d", path, authorization, rec.Code, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestVersionRange(t *testing.T) {
+	s := New(WithMiddleware(), WithVersions(VersionByPath(), "v1", "v2", "v3"))
+	hello := func(c echo.Context, in struct{}, out *string) error { return nil }
+	var applied int
+	counter := operationOption(func(op *operation) { applied++ })
+	s.GET("/hello", NewHandler(hello, WithVersionRange("v2", ""), counter))
+
+	var versions []string
+	for _, op := range s.Operations() {
+		versions = append(versions, op.Version)
+	}
+	if want := []string{"v2", "v3"}; !slices.Equal(versions, want) {
+		t.Errorf("got versions %q, want %q", versions, want)
+	}
+	if applied != 3 {
+		t.Errorf("options are applied %d times, want once per version", applied)
+	}
+
+	defer func() {
+		if r := recover(); r != "rest: GET /typo: unknown version in range v1..v4" {
+			t.Errorf("got panic %v", r)
+		}
+	}()
+	s.GET("/typo", NewHandler(hello, WithVersionRange("v1", "v4")))
+}